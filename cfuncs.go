@@ -124,6 +124,17 @@ void closeCallback_cgo(virConnectPtr conn, int reason, void *opaque)
     closeCallback(conn, reason, (long)opaque);
 }
 
+void streamEventCallback_cgo(virStreamPtr st, int events, void *opaque)
+{
+    streamEventCallback(st, events, (long)opaque);
+}
+
+int virStreamEventAddCallback_cgo(virStreamPtr st, int events, long goCallbackId)
+{
+    void *id = (void*)goCallbackId;
+    return virStreamEventAddCallback(st, events, streamEventCallback_cgo, id, freeGoCallback_cgo);
+}
+
 int authCb(virConnectCredentialPtr cred, unsigned int ncred, void *cbdata)
 {
 	int i;
@@ -0,0 +1,71 @@
+package libvirt
+
+/*
+#cgo LDFLAGS: -lvirt
+#include <libvirt/libvirt.h>
+#include <libvirt/virterror.h>
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"unsafe"
+)
+
+// DomainConsole is a connection to a console or serial device of a domain,
+// as returned by OpenConsole. It implements io.ReadWriteCloser over a
+// non-blocking stream. Read and Write wait for stream events, so the
+// application must run an event loop, see EventRegisterDefaultImpl and
+// EventRunDefaultImpl. Close may be called from another goroutine to
+// unblock a pending Read or Write, which then return io.ErrClosedPipe.
+type DomainConsole struct {
+	*eventStream
+}
+
+// OpenConsole connects to the console devname of a running domain, or to
+// its first console or serial device if devname is empty. With
+// VIR_DOMAIN_CONSOLE_FORCE an existing session on the device is
+// disconnected; with VIR_DOMAIN_CONSOLE_SAFE the connection fails if the
+// driver cannot guarantee exclusive access to it.
+func (d *VirDomain) OpenConsole(devname string, flags uint32) (*DomainConsole, error) {
+	var cDevname *C.char
+	if devname != "" {
+		cDevname = C.CString(devname)
+		defer C.free(unsafe.Pointer(cDevname))
+	}
+
+	stream, err := openEventStream(d, func(st C.virStreamPtr) C.int {
+		return C.virDomainOpenConsole(d.ptr, cDevname, st, C.uint(flags))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &DomainConsole{stream}, nil
+}
+
+// DomainChannel is a connection to a channel device of a domain, such as a
+// virtio-serial port, as returned by OpenChannel. It behaves like
+// DomainConsole.
+type DomainChannel struct {
+	*eventStream
+}
+
+// OpenChannel connects to the channel name of a running domain, given by
+// its alias or its target name such as "org.qemu.guest_agent.0", or to its
+// first channel if name is empty. With VIR_DOMAIN_CHANNEL_FORCE an
+// existing session on the channel is disconnected.
+func (d *VirDomain) OpenChannel(name string, flags uint32) (*DomainChannel, error) {
+	var cName *C.char
+	if name != "" {
+		cName = C.CString(name)
+		defer C.free(unsafe.Pointer(cName))
+	}
+
+	stream, err := openEventStream(d, func(st C.virStreamPtr) C.int {
+		return C.virDomainOpenChannel(d.ptr, cName, st, C.uint(flags))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &DomainChannel{stream}, nil
+}
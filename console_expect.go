@@ -0,0 +1,235 @@
+package libvirt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ansiEscape matches the terminal escape sequences that consoles sprinkle
+// through their output: CSI sequences such as colors and cursor moves, OSC
+// sequences such as window titles, character set selections and two byte
+// escapes such as cursor saves.
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-?]*[ -/]*[@-~]|\x1b\\][^\x07\x1b]*(\x07|\x1b\\\\)|\x1b[()][ -~]|\x1b[0-Z\\\\-_]")
+
+// ansiMaxPartial bounds how long an escape sequence split across reads can
+// be before its start is taken as plain output.
+const ansiMaxPartial = 32
+
+// StripANSI removes terminal escape sequences from s.
+func StripANSI(s string) string {
+	return ansiEscape.ReplaceAllString(s, "")
+}
+
+// ErrExpectTimeout is returned by Expect when the pattern did not show up
+// in time.
+var ErrExpectTimeout = errors.New("timed out waiting for console output")
+
+var (
+	DefaultLoginPrompt    = regexp.MustCompile(`(?i)login:\s*$`)
+	DefaultPasswordPrompt = regexp.MustCompile(`(?i)password:\s*$`)
+	DefaultShellPrompt    = regexp.MustCompile(`[#$>]\s*$`)
+	loginIncorrect        = regexp.MustCompile(`(?i)login incorrect|authentication failure`)
+)
+
+// ConsoleExpecter drives a text console, typically the DomainConsole
+// returned by OpenConsole, the way a person would: wait for some output,
+// type an answer. Output is matched with escape sequences stripped and
+// is consumed up to the end of each match, so successive calls to Expect
+// move forward through it.
+type ConsoleExpecter struct {
+	rw io.ReadWriter
+
+	// Prompts used by Login, DefaultLoginPrompt, DefaultPasswordPrompt and
+	// DefaultShellPrompt unless changed.
+	LoginPrompt    *regexp.Regexp
+	PasswordPrompt *regexp.Regexp
+	ShellPrompt    *regexp.Regexp
+
+	lock       sync.Mutex
+	changed    chan struct{} // Closed and replaced whenever output arrives
+	raw        io.Writer
+	transcript bytes.Buffer // Output with escape sequences stripped
+	partial    []byte       // Possibly incomplete escape sequence
+	consumed   int          // Offset in transcript Expect has matched up to
+	err        error        // Read error that stopped the reader
+}
+
+// NewConsoleExpecter starts reading the output of rw in the background.
+// The raw output is also copied to raw, if not nil. Reading stops when rw
+// returns an error, e.g. once it is closed.
+func NewConsoleExpecter(rw io.ReadWriter, raw io.Writer) *ConsoleExpecter {
+	e := &ConsoleExpecter{
+		rw:             rw,
+		raw:            raw,
+		LoginPrompt:    DefaultLoginPrompt,
+		PasswordPrompt: DefaultPasswordPrompt,
+		ShellPrompt:    DefaultShellPrompt,
+		changed:        make(chan struct{}),
+	}
+	go e.readLoop()
+	return e
+}
+
+func (e *ConsoleExpecter) readLoop() {
+	buf := make([]byte, 4096)
+	for {
+		n, err := e.rw.Read(buf)
+		e.lock.Lock()
+		if n > 0 {
+			if e.raw != nil {
+				e.raw.Write(buf[:n])
+			}
+			e.appendOutput(buf[:n], err != nil)
+		} else if err != nil {
+			e.appendOutput(nil, true)
+		}
+		if err != nil {
+			e.err = err
+		}
+		close(e.changed)
+		e.changed = make(chan struct{})
+		e.lock.Unlock()
+		if err != nil {
+			return
+		}
+	}
+}
+
+// appendOutput strips data and adds it to the transcript. The tail of an
+// escape sequence may come with the next read, so a trailing escape
+// character is held back unless this is the last output.
+func (e *ConsoleExpecter) appendOutput(data []byte, last bool) {
+	pending := ansiEscape.ReplaceAll(append(e.partial, data...), nil)
+	e.partial = nil
+	if i := bytes.LastIndexByte(pending, '\x1b'); i >= 0 && !last && len(pending)-i < ansiMaxPartial {
+		e.partial = append([]byte(nil), pending[i:]...)
+		pending = pending[:i]
+	}
+	e.transcript.Write(pending)
+}
+
+// Expect waits up to timeout for output matching re and returns the match
+// followed by its submatches. Output up to the end of the match is
+// consumed. ErrExpectTimeout is returned on timeout, and the read error,
+// e.g. io.EOF, if the console went away before the pattern showed up.
+func (e *ConsoleExpecter) Expect(re *regexp.Regexp, timeout time.Duration) ([]string, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		e.lock.Lock()
+		pending := e.transcript.String()[e.consumed:]
+		if loc := re.FindStringSubmatchIndex(pending); loc != nil {
+			e.consumed += loc[1]
+			e.lock.Unlock()
+			match := make([]string, len(loc)/2)
+			for i := range match {
+				if loc[2*i] >= 0 {
+					match[i] = pending[loc[2*i]:loc[2*i+1]]
+				}
+			}
+			return match, nil
+		}
+		if e.err != nil {
+			err := e.err
+			e.lock.Unlock()
+			return nil, err
+		}
+		changed := e.changed
+		e.lock.Unlock()
+
+		select {
+		case <-changed:
+		case <-timer.C:
+			return nil, ErrExpectTimeout
+		}
+	}
+}
+
+// Send types s on the console as is.
+func (e *ConsoleExpecter) Send(s string) error {
+	_, err := io.WriteString(e.rw, s)
+	return err
+}
+
+// SendLine types s followed by a carriage return, which is what the Enter
+// key sends.
+func (e *ConsoleExpecter) SendLine(s string) error {
+	return e.Send(s + "\r")
+}
+
+// Transcript returns all the output received so far, escape sequences
+// stripped.
+func (e *ConsoleExpecter) Transcript() string {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.transcript.String()
+}
+
+// Login waits for the login prompt and logs in as user, answering the
+// password prompt if one is shown, then waits for the shell prompt. Each
+// step waits up to timeout. A rejected login is reported as an error.
+func (e *ConsoleExpecter) Login(user, password string, timeout time.Duration) error {
+	if _, err := e.Expect(e.LoginPrompt, timeout); err != nil {
+		return fmt.Errorf("waiting for login prompt: %s", err)
+	}
+	if err := e.SendLine(user); err != nil {
+		return err
+	}
+	afterUser := alternation(e.PasswordPrompt, e.ShellPrompt)
+	match, err := e.Expect(afterUser, timeout)
+	if err != nil {
+		return fmt.Errorf("waiting for password or shell prompt: %s", err)
+	}
+	if e.PasswordPrompt.MatchString(match[0]) {
+		if err := e.SendLine(password); err != nil {
+			return err
+		}
+		match, err = e.Expect(alternation(loginIncorrect, e.LoginPrompt, e.ShellPrompt), timeout)
+		if err != nil {
+			return fmt.Errorf("waiting for shell prompt: %s", err)
+		}
+		if loginIncorrect.MatchString(match[0]) || e.LoginPrompt.MatchString(match[0]) {
+			return fmt.Errorf("login as %s failed", user)
+		}
+	}
+	return nil
+}
+
+// Run types cmd and returns its output: the lines between the echoed
+// command line and the next shell prompt.
+func (e *ConsoleExpecter) Run(cmd string, timeout time.Duration) (string, error) {
+	if err := e.SendLine(cmd); err != nil {
+		return "", err
+	}
+	if _, err := e.Expect(regexp.MustCompile(regexp.QuoteMeta(cmd)+`\r?\n`), timeout); err != nil {
+		return "", fmt.Errorf("waiting for command echo: %s", err)
+	}
+	e.lock.Lock()
+	start := e.consumed
+	e.lock.Unlock()
+	if _, err := e.Expect(e.ShellPrompt, timeout); err != nil {
+		return "", fmt.Errorf("waiting for shell prompt: %s", err)
+	}
+	e.lock.Lock()
+	output := e.transcript.String()[start:e.consumed]
+	e.lock.Unlock()
+	return output[:strings.LastIndex(output, "\n")+1], nil
+}
+
+// alternation returns a pattern matching any of res.
+func alternation(res ...*regexp.Regexp) *regexp.Regexp {
+	var pattern bytes.Buffer
+	for i, re := range res {
+		if i > 0 {
+			pattern.WriteByte('|')
+		}
+		pattern.WriteString("(?:" + re.String() + ")")
+	}
+	return regexp.MustCompile(pattern.String())
+}
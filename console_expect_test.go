@@ -0,0 +1,117 @@
+package libvirt
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeGuest plays a serial getty and shell on the far end of an in-memory
+// pipe. Colors and cursor moves are mixed into its output, and the login
+// prompt is split in the middle of an escape sequence.
+func fakeGuest(conn net.Conn, password string) {
+	defer conn.Close()
+	in := bufio.NewReader(conn)
+	readLine := func() string {
+		line, err := in.ReadString('\r')
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSuffix(line, "\r")
+	}
+
+	io.WriteString(conn, "\x1b[2J\x1b[HDebian GNU/Linux ttyS0\r\n\r\nguest \x1b[1")
+	io.WriteString(conn, ";32mlogin:\x1b[0m ")
+	for {
+		user := readLine()
+		io.WriteString(conn, user+"\r\nPassword: ")
+		if readLine() == password {
+			break
+		}
+		io.WriteString(conn, "\r\n\r\nLogin incorrect\r\nguest login: ")
+	}
+	io.WriteString(conn, "\r\n\x1b]0;root@guest\x07root@guest:~# ")
+	for {
+		cmd := readLine()
+		if cmd == "" {
+			return
+		}
+		io.WriteString(conn, cmd+"\r\n")
+		if cmd == "uname" {
+			io.WriteString(conn, "Linux\r\n")
+		}
+		io.WriteString(conn, "root@guest:~# ")
+	}
+}
+
+func TestStripANSI(t *testing.T) {
+	in := "\x1b[2J\x1b[1;32mgreen\x1b[0m \x1b]0;title\x07text\x1b7\x1b[?25l"
+	if out := StripANSI(in); out != "green text" {
+		t.Errorf("StripANSI(%q) == %q, expected %q", in, out, "green text")
+	}
+}
+
+func TestConsoleExpecterLogin(t *testing.T) {
+	local, remote := net.Pipe()
+	defer local.Close()
+	go fakeGuest(remote, "secret")
+
+	var raw bytes.Buffer
+	e := NewConsoleExpecter(local, &raw)
+	if err := e.Login("root", "wrong", time.Second); err == nil {
+		t.Fatal("expected login with a wrong password to fail")
+	}
+	e.Send("root\r")
+	if _, err := e.Expect(e.PasswordPrompt, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	e.SendLine("secret")
+	if _, err := e.Expect(e.ShellPrompt, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := e.Run("uname", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != "Linux\r\n" {
+		t.Errorf("Run() == %q, expected %q", output, "Linux\r\n")
+	}
+
+	transcript := e.Transcript()
+	if strings.Contains(transcript, "\x1b") {
+		t.Errorf("escape sequences left in transcript %q", transcript)
+	}
+	if !strings.Contains(transcript, "guest login: root\r\n") {
+		t.Errorf("login prompt missing from transcript %q", transcript)
+	}
+	if !strings.Contains(raw.String(), "\x1b[1;32mlogin:") {
+		t.Errorf("raw output %q lacks escape sequences", raw.String())
+	}
+}
+
+func TestConsoleExpecterSubmatch(t *testing.T) {
+	local, remote := net.Pipe()
+	e := NewConsoleExpecter(local, nil)
+	go io.WriteString(remote, "Booting kernel 4.9.0-3-amd64\r\n")
+	match, err := e.Expect(regexp.MustCompile(`kernel (\S+)`), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(match) != 2 || match[1] != "4.9.0-3-amd64" {
+		t.Errorf("Expect() == %q", match)
+	}
+
+	if _, err := e.Expect(regexp.MustCompile(`kernel`), 10*time.Millisecond); err != ErrExpectTimeout {
+		t.Errorf("Expect() of consumed output == %v, expected %v", err, ErrExpectTimeout)
+	}
+	remote.Close()
+	if _, err := e.Expect(regexp.MustCompile(`never`), time.Second); err != io.EOF {
+		t.Errorf("Expect() after close == %v, expected %v", err, io.EOF)
+	}
+}
@@ -0,0 +1,408 @@
+package libvirt
+
+/*
+#cgo LDFLAGS: -lvirt
+#include <libvirt/libvirt.h>
+#include <libvirt/virterror.h>
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrConsoleWriterBusy     = errors.New("console writer is held by another client")
+	ErrConsoleWriterReleased = errors.New("console writer was released")
+	ErrConsoleDisconnected   = errors.New("console is disconnected")
+)
+
+// consoleMuxBuffer is the number of chunks of output queued for each
+// subscriber. A subscriber that falls further behind misses output.
+const consoleMuxBuffer = 256
+
+// consoleMuxRetryInterval is how often a lost console is reopened when no
+// lifecycle event says the domain is back.
+const consoleMuxRetryInterval = time.Second
+
+// ConsoleMuxOptions configures a ConsoleMux.
+type ConsoleMuxOptions struct {
+	Devname string // Console device, the first one if empty
+	Flags   uint32 // Passed to OpenConsole, e.g. VIR_DOMAIN_CONSOLE_FORCE
+
+	// Output is recorded to RecordPath as an asciicast v2 file, if set.
+	// Once it would grow over RecordMaxSize bytes, it is rotated to
+	// RecordPath.1 and older recordings are shifted, keeping RecordKeep
+	// of them. A RecordMaxSize of zero disables rotation.
+	RecordPath    string
+	RecordMaxSize int64
+	RecordKeep    int
+	Width         int // Terminal size written to recordings, 80x24 if zero
+	Height        int
+}
+
+// ConsoleMux shares the console of a domain between several clients. It
+// holds the console stream, hands its output to every subscriber and lets
+// one client at a time write to it. When the domain is restarted or the
+// stream is lost, the console is reopened; output in between is lost and
+// writes fail with ErrConsoleDisconnected.
+//
+// Restarts are noticed through lifecycle events, which need an event loop;
+// otherwise the console is reopened once the old stream fails.
+type ConsoleMux struct {
+	open     func() (io.ReadWriteCloser, error)
+	recorder *asciicastRecorder
+	restart  chan struct{}
+	done     chan struct{}
+	stopped  chan struct{}
+	cleanup  func()
+
+	lock        sync.Mutex
+	console     io.ReadWriteCloser // nil while disconnected
+	subscribers map[chan []byte]struct{}
+	writer      *consoleMuxWriter
+	recordErr   error
+	closed      bool
+}
+
+// NewConsoleMux opens the console of the domain and starts sharing it. The
+// domain must stay valid until the ConsoleMux is closed.
+func NewConsoleMux(d *VirDomain, opts ConsoleMuxOptions) (*ConsoleMux, error) {
+	m, err := newConsoleMux(func() (io.ReadWriteCloser, error) {
+		return d.OpenConsole(opts.Devname, opts.Flags)
+	}, opts, time.Now)
+	if err != nil {
+		return nil, err
+	}
+
+	callback := DomainEventCallback(func(c *VirConnection, dom *VirDomain, event interface{}, f func()) int {
+		if e, ok := event.(DomainLifecycleEvent); ok && e.Event == VIR_DOMAIN_EVENT_STARTED {
+			m.reconnect()
+		}
+		return 0
+	})
+	conn := VirConnection{ptr: C.virDomainGetConnect(d.ptr)}
+	if callbackId := conn.DomainEventRegister(*d, VIR_DOMAIN_EVENT_ID_LIFECYCLE, &callback, nil); callbackId != -1 {
+		m.cleanup = func() {
+			conn.DomainEventDeregister(callbackId)
+		}
+	}
+	return m, nil
+}
+
+func newConsoleMux(open func() (io.ReadWriteCloser, error), opts ConsoleMuxOptions, clock func() time.Time) (*ConsoleMux, error) {
+	m := &ConsoleMux{
+		open:        open,
+		restart:     make(chan struct{}, 1),
+		done:        make(chan struct{}),
+		stopped:     make(chan struct{}),
+		subscribers: make(map[chan []byte]struct{}),
+	}
+	if opts.RecordPath != "" {
+		recorder, err := newAsciicastRecorder(opts, clock)
+		if err != nil {
+			return nil, err
+		}
+		m.recorder = recorder
+	}
+	go m.run()
+	return m, nil
+}
+
+func (m *ConsoleMux) run() {
+	defer close(m.stopped)
+	for {
+		if console, err := m.open(); err == nil {
+			m.lock.Lock()
+			if m.closed {
+				m.lock.Unlock()
+				console.Close()
+				return
+			}
+			m.console = console
+			m.lock.Unlock()
+
+			m.pump(console)
+
+			m.lock.Lock()
+			m.console = nil
+			m.lock.Unlock()
+			console.Close()
+		}
+
+		select {
+		case <-m.done:
+			return
+		case <-m.restart:
+		case <-time.After(consoleMuxRetryInterval):
+		}
+	}
+}
+
+// pump hands the console output to the subscribers and the recorder until
+// the console fails or is closed.
+func (m *ConsoleMux) pump(console io.Reader) {
+	buf := make([]byte, 4096)
+	for {
+		n, err := console.Read(buf)
+		if n > 0 {
+			data := append([]byte(nil), buf[:n]...)
+			m.lock.Lock()
+			for subscriber := range m.subscribers {
+				select {
+				case subscriber <- data:
+				default:
+				}
+			}
+			if m.recorder != nil && m.recordErr == nil {
+				m.recordErr = m.recorder.write(data)
+			}
+			m.lock.Unlock()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// reconnect drops the current console, if any, and opens it again.
+func (m *ConsoleMux) reconnect() {
+	m.lock.Lock()
+	if m.console != nil {
+		m.console.Close()
+	}
+	m.lock.Unlock()
+	select {
+	case m.restart <- struct{}{}:
+	default:
+	}
+}
+
+// Subscribe returns a channel receiving the console output from now on,
+// and a function to stop the subscription. The channel is closed when the
+// subscription is stopped or the ConsoleMux is closed.
+func (m *ConsoleMux) Subscribe() (<-chan []byte, func()) {
+	subscriber := make(chan []byte, consoleMuxBuffer)
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.closed {
+		close(subscriber)
+		return subscriber, func() {}
+	}
+	m.subscribers[subscriber] = struct{}{}
+	return subscriber, func() {
+		m.lock.Lock()
+		defer m.lock.Unlock()
+		if _, ok := m.subscribers[subscriber]; ok {
+			delete(m.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+}
+
+type consoleMuxWriter struct {
+	m *ConsoleMux
+}
+
+// AcquireWriter returns the right to type on the console, which must be
+// given back by closing the returned writer. ErrConsoleWriterBusy is
+// returned while another client holds it.
+func (m *ConsoleMux) AcquireWriter() (io.WriteCloser, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.closed {
+		return nil, io.ErrClosedPipe
+	}
+	if m.writer != nil {
+		return nil, ErrConsoleWriterBusy
+	}
+	m.writer = &consoleMuxWriter{m}
+	return m.writer, nil
+}
+
+func (w *consoleMuxWriter) Write(p []byte) (int, error) {
+	w.m.lock.Lock()
+	if w.m.writer != w {
+		w.m.lock.Unlock()
+		return 0, ErrConsoleWriterReleased
+	}
+	console := w.m.console
+	w.m.lock.Unlock()
+	if console == nil {
+		return 0, ErrConsoleDisconnected
+	}
+	return console.Write(p)
+}
+
+func (w *consoleMuxWriter) Close() error {
+	w.m.lock.Lock()
+	defer w.m.lock.Unlock()
+	if w.m.writer == w {
+		w.m.writer = nil
+	}
+	return nil
+}
+
+// RecordError returns the error that stopped the recording, if any.
+func (m *ConsoleMux) RecordError() error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.recordErr
+}
+
+// Close disconnects from the console, ends all subscriptions and the
+// recording.
+func (m *ConsoleMux) Close() error {
+	m.lock.Lock()
+	if m.closed {
+		m.lock.Unlock()
+		return nil
+	}
+	m.closed = true
+	close(m.done)
+	if m.console != nil {
+		m.console.Close()
+	}
+	m.lock.Unlock()
+
+	<-m.stopped
+	if m.cleanup != nil {
+		m.cleanup()
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for subscriber := range m.subscribers {
+		delete(m.subscribers, subscriber)
+		close(subscriber)
+	}
+	m.writer = nil
+	if m.recorder != nil {
+		return m.recorder.close()
+	}
+	return nil
+}
+
+// asciicastRecorder writes output in the asciicast v2 format: a JSON header
+// line followed by one [elapsed seconds, "o", text] line per chunk.
+type asciicastRecorder struct {
+	path    string
+	maxSize int64
+	keep    int
+	width   int
+	height  int
+	clock   func() time.Time
+
+	file    *os.File
+	size    int64
+	start   time.Time
+	partial []byte // Incomplete UTF-8 sequence at the end of the last chunk
+}
+
+func newAsciicastRecorder(opts ConsoleMuxOptions, clock func() time.Time) (*asciicastRecorder, error) {
+	r := &asciicastRecorder{
+		path:    opts.RecordPath,
+		maxSize: opts.RecordMaxSize,
+		keep:    opts.RecordKeep,
+		width:   opts.Width,
+		height:  opts.Height,
+		clock:   clock,
+	}
+	if r.width == 0 {
+		r.width = 80
+	}
+	if r.height == 0 {
+		r.height = 24
+	}
+	if err := r.create(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *asciicastRecorder) create() error {
+	file, err := os.OpenFile(r.path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0640)
+	if err != nil {
+		return err
+	}
+	r.file = file
+	r.size = 0
+	r.start = r.clock()
+	header, _ := json.Marshal(struct {
+		Version   int   `json:"version"`
+		Width     int   `json:"width"`
+		Height    int   `json:"height"`
+		Timestamp int64 `json:"timestamp"`
+	}{2, r.width, r.height, r.start.Unix()})
+	return r.writeLine(header)
+}
+
+func (r *asciicastRecorder) writeLine(line []byte) error {
+	n, err := r.file.Write(append(line, '\n'))
+	r.size += int64(n)
+	return err
+}
+
+// rotate shifts the recordings, RecordPath becoming RecordPath.1, and
+// starts a new one.
+func (r *asciicastRecorder) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	if r.keep < 1 {
+		os.Remove(r.path)
+	} else {
+		os.Remove(fmt.Sprintf("%s.%d", r.path, r.keep))
+		for i := r.keep - 1; i > 0; i-- {
+			os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+		}
+		if err := os.Rename(r.path, r.path+".1"); err != nil {
+			return err
+		}
+	}
+	return r.create()
+}
+
+func (r *asciicastRecorder) write(data []byte) error {
+	data = append(r.partial, data...)
+	r.partial = nil
+	// JSON strings must be valid UTF-8, so a character split between
+	// chunks is written with the next one.
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				r.partial = append([]byte(nil), data[i:]...)
+				data = data[:i]
+			}
+			break
+		}
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	now := r.clock()
+	line, err := json.Marshal([]interface{}{now.Sub(r.start).Seconds(), "o", string(data)})
+	if err != nil {
+		return err
+	}
+	if r.maxSize > 0 && r.size+int64(len(line))+1 > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return err
+		}
+		line, _ = json.Marshal([]interface{}{0.0, "o", string(data)})
+	}
+	return r.writeLine(line)
+}
+
+func (r *asciicastRecorder) close() error {
+	return r.file.Close()
+}
@@ -0,0 +1,182 @@
+package libvirt
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// buildTestConsoleMux returns a ConsoleMux over in-memory consoles. The
+// guest end of each console it opens is sent to the returned channel.
+func buildTestConsoleMux(t *testing.T, opts ConsoleMuxOptions) (*ConsoleMux, chan net.Conn) {
+	guests := make(chan net.Conn, 4)
+	m, err := newConsoleMux(func() (io.ReadWriteCloser, error) {
+		local, remote := net.Pipe()
+		guests <- remote
+		return local, nil
+	}, opts, time.Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m, guests
+}
+
+func expectConsoleOutput(t *testing.T, output <-chan []byte, expected string) {
+	select {
+	case data := <-output:
+		if string(data) != expected {
+			t.Errorf("got output %q, expected %q", data, expected)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for %q", expected)
+	}
+}
+
+func TestConsoleMuxFanOut(t *testing.T) {
+	m, guests := buildTestConsoleMux(t, ConsoleMuxOptions{})
+	defer m.Close()
+	guest := <-guests
+
+	first, unsubscribe := m.Subscribe()
+	second, _ := m.Subscribe()
+	io.WriteString(guest, "login: ")
+	expectConsoleOutput(t, first, "login: ")
+	expectConsoleOutput(t, second, "login: ")
+
+	unsubscribe()
+	if _, ok := <-first; ok {
+		t.Error("expected unsubscribed channel to be closed")
+	}
+	io.WriteString(guest, "Password: ")
+	expectConsoleOutput(t, second, "Password: ")
+
+	m.Close()
+	if _, ok := <-second; ok {
+		t.Error("expected channel to be closed with the mux")
+	}
+}
+
+func TestConsoleMuxWriter(t *testing.T) {
+	m, guests := buildTestConsoleMux(t, ConsoleMuxOptions{})
+	defer m.Close()
+	guest := <-guests
+
+	w, err := m.AcquireWriter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.AcquireWriter(); err != ErrConsoleWriterBusy {
+		t.Errorf("AcquireWriter() == %v, expected %v", err, ErrConsoleWriterBusy)
+	}
+	go io.WriteString(w, "root\r")
+	line, err := bufio.NewReader(guest).ReadString('\r')
+	if err != nil || line != "root\r" {
+		t.Errorf("guest read %q, %v", line, err)
+	}
+
+	w.Close()
+	if _, err := w.Write([]byte("x")); err != ErrConsoleWriterReleased {
+		t.Errorf("Write() after Close() == %v, expected %v", err, ErrConsoleWriterReleased)
+	}
+	w, err = m.AcquireWriter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+}
+
+func TestConsoleMuxReconnect(t *testing.T) {
+	m, guests := buildTestConsoleMux(t, ConsoleMuxOptions{})
+	defer m.Close()
+	output, _ := m.Subscribe()
+	guest := <-guests
+
+	m.reconnect()
+	if _, err := guest.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("old console read %v, expected %v", err, io.EOF)
+	}
+	select {
+	case guest = <-guests:
+	case <-time.After(time.Second):
+		t.Fatal("console was not reopened")
+	}
+	io.WriteString(guest, "booting")
+	expectConsoleOutput(t, output, "booting")
+}
+
+func readAsciicast(t *testing.T, path string) (map[string]interface{}, []string) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	var header map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &header); err != nil {
+		t.Fatal(err)
+	}
+	var output []string
+	for _, line := range lines[1:] {
+		var event []interface{}
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatal(err)
+		}
+		if len(event) != 3 || event[1] != "o" {
+			t.Fatalf("malformed event %s", line)
+		}
+		output = append(output, event[2].(string))
+	}
+	return header, output
+}
+
+func TestAsciicastRecorderRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "libvirt-go-asciicast")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	now := time.Unix(1500000000, 0)
+	clock := func() time.Time {
+		now = now.Add(500 * time.Millisecond)
+		return now
+	}
+	path := filepath.Join(dir, "console.cast")
+	r, err := newAsciicastRecorder(ConsoleMuxOptions{
+		RecordPath:    path,
+		RecordMaxSize: 100,
+		RecordKeep:    1,
+	}, clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, chunk := range []string{"first", "second\xe2\x82", "\xac", "third", "fourth"} {
+		if err := r.write([]byte(chunk)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	header, output := readAsciicast(t, path+".1")
+	if header["version"] != 2.0 || header["width"] != 80.0 || header["height"] != 24.0 {
+		t.Errorf("unexpected header %v", header)
+	}
+	if expected := []string{"€", "third"}; !reflect.DeepEqual(output, expected) {
+		t.Errorf("rotated recording has %q, expected %q", output, expected)
+	}
+	if _, output = readAsciicast(t, path); !reflect.DeepEqual(output, []string{"fourth"}) {
+		t.Errorf("recording has %q", output)
+	}
+	if _, err := os.Stat(path + ".2"); !os.IsNotExist(err) {
+		t.Errorf("expected only one rotated recording, got %v", err)
+	}
+}
@@ -9,6 +9,374 @@ package libvirt
 #ifndef VIR_DOMAIN_UNDEFINE_NVRAM
 #define VIR_DOMAIN_UNDEFINE_NVRAM (1 << 2)
 #endif
+
+#ifndef VIR_MIGRATE_AUTO_CONVERGE
+#define VIR_MIGRATE_AUTO_CONVERGE (1 << 13)
+#endif
+
+#ifndef VIR_MIGRATE_RDMA_PIN_ALL
+#define VIR_MIGRATE_RDMA_PIN_ALL (1 << 14)
+#endif
+
+#ifndef VIR_MIGRATE_POSTCOPY
+#define VIR_MIGRATE_POSTCOPY (1 << 15)
+#endif
+
+#ifndef VIR_MIGRATE_TLS
+#define VIR_MIGRATE_TLS (1 << 16)
+#endif
+
+#ifndef VIR_MIGRATE_PARALLEL
+#define VIR_MIGRATE_PARALLEL (1 << 17)
+#endif
+
+#ifndef VIR_MIGRATE_PARAM_LISTEN_ADDRESS
+#define VIR_MIGRATE_PARAM_LISTEN_ADDRESS "listen_address"
+#endif
+
+#ifndef VIR_MIGRATE_PARAM_MIGRATE_DISKS
+#define VIR_MIGRATE_PARAM_MIGRATE_DISKS "migrate_disks"
+#endif
+
+#ifndef VIR_MIGRATE_PARAM_DISKS_PORT
+#define VIR_MIGRATE_PARAM_DISKS_PORT "disks_port"
+#endif
+
+#ifndef VIR_MIGRATE_PARAM_COMPRESSION
+#define VIR_MIGRATE_PARAM_COMPRESSION "compression"
+#endif
+
+#ifndef VIR_MIGRATE_PARAM_COMPRESSION_MT_LEVEL
+#define VIR_MIGRATE_PARAM_COMPRESSION_MT_LEVEL "compression.mt.level"
+#endif
+
+#ifndef VIR_MIGRATE_PARAM_COMPRESSION_MT_THREADS
+#define VIR_MIGRATE_PARAM_COMPRESSION_MT_THREADS "compression.mt.threads"
+#endif
+
+#ifndef VIR_MIGRATE_PARAM_COMPRESSION_MT_DTHREADS
+#define VIR_MIGRATE_PARAM_COMPRESSION_MT_DTHREADS "compression.mt.dthreads"
+#endif
+
+#ifndef VIR_MIGRATE_PARAM_COMPRESSION_XBZRLE_CACHE
+#define VIR_MIGRATE_PARAM_COMPRESSION_XBZRLE_CACHE "compression.xbzrle.cache"
+#endif
+
+#ifndef VIR_MIGRATE_PARAM_PERSIST_XML
+#define VIR_MIGRATE_PARAM_PERSIST_XML "persistent_xml"
+#endif
+
+#ifndef VIR_MIGRATE_PARAM_AUTO_CONVERGE_INITIAL
+#define VIR_MIGRATE_PARAM_AUTO_CONVERGE_INITIAL "auto_converge.initial"
+#endif
+
+#ifndef VIR_MIGRATE_PARAM_AUTO_CONVERGE_INCREMENT
+#define VIR_MIGRATE_PARAM_AUTO_CONVERGE_INCREMENT "auto_converge.increment"
+#endif
+
+#ifndef VIR_MIGRATE_PARAM_PARALLEL_CONNECTIONS
+#define VIR_MIGRATE_PARAM_PARALLEL_CONNECTIONS "parallel.connections"
+#endif
+
+#ifndef VIR_DOMAIN_JOB_STATS_COMPLETED
+#define VIR_DOMAIN_JOB_STATS_COMPLETED (1 << 0)
+#endif
+
+#ifndef VIR_DOMAIN_JOB_OPERATION_UNKNOWN
+#define VIR_DOMAIN_JOB_OPERATION_UNKNOWN 0
+#endif
+
+#ifndef VIR_DOMAIN_JOB_OPERATION_START
+#define VIR_DOMAIN_JOB_OPERATION_START 1
+#endif
+
+#ifndef VIR_DOMAIN_JOB_OPERATION_SAVE
+#define VIR_DOMAIN_JOB_OPERATION_SAVE 2
+#endif
+
+#ifndef VIR_DOMAIN_JOB_OPERATION_RESTORE
+#define VIR_DOMAIN_JOB_OPERATION_RESTORE 3
+#endif
+
+#ifndef VIR_DOMAIN_JOB_OPERATION_MIGRATION_IN
+#define VIR_DOMAIN_JOB_OPERATION_MIGRATION_IN 4
+#endif
+
+#ifndef VIR_DOMAIN_JOB_OPERATION_MIGRATION_OUT
+#define VIR_DOMAIN_JOB_OPERATION_MIGRATION_OUT 5
+#endif
+
+#ifndef VIR_DOMAIN_JOB_OPERATION_SNAPSHOT
+#define VIR_DOMAIN_JOB_OPERATION_SNAPSHOT 6
+#endif
+
+#ifndef VIR_DOMAIN_JOB_OPERATION_SNAPSHOT_REVERT
+#define VIR_DOMAIN_JOB_OPERATION_SNAPSHOT_REVERT 7
+#endif
+
+#ifndef VIR_DOMAIN_JOB_OPERATION_DUMP
+#define VIR_DOMAIN_JOB_OPERATION_DUMP 8
+#endif
+
+#ifndef VIR_DOMAIN_JOB_OPERATION
+#define VIR_DOMAIN_JOB_OPERATION "operation"
+#endif
+
+#ifndef VIR_DOMAIN_JOB_TIME_ELAPSED_NET
+#define VIR_DOMAIN_JOB_TIME_ELAPSED_NET "time_elapsed_net"
+#endif
+
+#ifndef VIR_DOMAIN_JOB_DOWNTIME_NET
+#define VIR_DOMAIN_JOB_DOWNTIME_NET "downtime_net"
+#endif
+
+#ifndef VIR_DOMAIN_JOB_SETUP_TIME
+#define VIR_DOMAIN_JOB_SETUP_TIME "setup_time"
+#endif
+
+#ifndef VIR_DOMAIN_JOB_MEMORY_BPS
+#define VIR_DOMAIN_JOB_MEMORY_BPS "memory_bps"
+#endif
+
+#ifndef VIR_DOMAIN_JOB_MEMORY_DIRTY_RATE
+#define VIR_DOMAIN_JOB_MEMORY_DIRTY_RATE "memory_dirty_rate"
+#endif
+
+#ifndef VIR_DOMAIN_JOB_MEMORY_PAGE_SIZE
+#define VIR_DOMAIN_JOB_MEMORY_PAGE_SIZE "memory_page_size"
+#endif
+
+#ifndef VIR_DOMAIN_JOB_MEMORY_ITERATION
+#define VIR_DOMAIN_JOB_MEMORY_ITERATION "memory_iteration"
+#endif
+
+#ifndef VIR_DOMAIN_JOB_DISK_BPS
+#define VIR_DOMAIN_JOB_DISK_BPS "disk_bps"
+#endif
+
+#ifndef VIR_DOMAIN_JOB_AUTO_CONVERGE_THROTTLE
+#define VIR_DOMAIN_JOB_AUTO_CONVERGE_THROTTLE "auto_converge_throttle"
+#endif
+
+#ifndef VIR_DOMAIN_EVENT_ID_BLOCK_JOB_2
+#define VIR_DOMAIN_EVENT_ID_BLOCK_JOB_2 16
+#endif
+
+#ifndef VIR_DOMAIN_BLOCK_JOB_TYPE_ACTIVE_COMMIT
+#define VIR_DOMAIN_BLOCK_JOB_TYPE_ACTIVE_COMMIT 4
+#endif
+
+#ifndef VIR_DOMAIN_BLOCK_COMMIT_ACTIVE
+#define VIR_DOMAIN_BLOCK_COMMIT_ACTIVE (1 << 2)
+#endif
+
+#ifndef VIR_DOMAIN_BLOCK_COMMIT_RELATIVE
+#define VIR_DOMAIN_BLOCK_COMMIT_RELATIVE (1 << 3)
+#endif
+
+#ifndef VIR_DOMAIN_BLOCK_COMMIT_BANDWIDTH_BYTES
+#define VIR_DOMAIN_BLOCK_COMMIT_BANDWIDTH_BYTES (1 << 4)
+#endif
+
+#ifndef VIR_DOMAIN_BLOCK_PULL_BANDWIDTH_BYTES
+#define VIR_DOMAIN_BLOCK_PULL_BANDWIDTH_BYTES (1 << 6)
+#endif
+
+#ifndef VIR_DOMAIN_BLOCK_REBASE_RELATIVE
+#define VIR_DOMAIN_BLOCK_REBASE_RELATIVE (1 << 4)
+#endif
+
+#ifndef VIR_DOMAIN_BLOCK_REBASE_COPY_DEV
+#define VIR_DOMAIN_BLOCK_REBASE_COPY_DEV (1 << 5)
+#endif
+
+#ifndef VIR_DOMAIN_BLOCK_REBASE_BANDWIDTH_BYTES
+#define VIR_DOMAIN_BLOCK_REBASE_BANDWIDTH_BYTES (1 << 6)
+#endif
+
+#ifndef VIR_DOMAIN_BLOCK_JOB_SPEED_BANDWIDTH_BYTES
+#define VIR_DOMAIN_BLOCK_JOB_SPEED_BANDWIDTH_BYTES (1 << 0)
+#endif
+
+#ifndef VIR_DOMAIN_BLOCK_IOTUNE_TOTAL_BYTES_SEC_MAX
+#define VIR_DOMAIN_BLOCK_IOTUNE_TOTAL_BYTES_SEC_MAX "total_bytes_sec_max"
+#endif
+
+#ifndef VIR_DOMAIN_BLOCK_IOTUNE_READ_BYTES_SEC_MAX
+#define VIR_DOMAIN_BLOCK_IOTUNE_READ_BYTES_SEC_MAX "read_bytes_sec_max"
+#endif
+
+#ifndef VIR_DOMAIN_BLOCK_IOTUNE_WRITE_BYTES_SEC_MAX
+#define VIR_DOMAIN_BLOCK_IOTUNE_WRITE_BYTES_SEC_MAX "write_bytes_sec_max"
+#endif
+
+#ifndef VIR_DOMAIN_BLOCK_IOTUNE_TOTAL_IOPS_SEC_MAX
+#define VIR_DOMAIN_BLOCK_IOTUNE_TOTAL_IOPS_SEC_MAX "total_iops_sec_max"
+#endif
+
+#ifndef VIR_DOMAIN_BLOCK_IOTUNE_READ_IOPS_SEC_MAX
+#define VIR_DOMAIN_BLOCK_IOTUNE_READ_IOPS_SEC_MAX "read_iops_sec_max"
+#endif
+
+#ifndef VIR_DOMAIN_BLOCK_IOTUNE_WRITE_IOPS_SEC_MAX
+#define VIR_DOMAIN_BLOCK_IOTUNE_WRITE_IOPS_SEC_MAX "write_iops_sec_max"
+#endif
+
+#ifndef VIR_DOMAIN_BLOCK_IOTUNE_TOTAL_BYTES_SEC_MAX_LENGTH
+#define VIR_DOMAIN_BLOCK_IOTUNE_TOTAL_BYTES_SEC_MAX_LENGTH "total_bytes_sec_max_length"
+#endif
+
+#ifndef VIR_DOMAIN_BLOCK_IOTUNE_READ_BYTES_SEC_MAX_LENGTH
+#define VIR_DOMAIN_BLOCK_IOTUNE_READ_BYTES_SEC_MAX_LENGTH "read_bytes_sec_max_length"
+#endif
+
+#ifndef VIR_DOMAIN_BLOCK_IOTUNE_WRITE_BYTES_SEC_MAX_LENGTH
+#define VIR_DOMAIN_BLOCK_IOTUNE_WRITE_BYTES_SEC_MAX_LENGTH "write_bytes_sec_max_length"
+#endif
+
+#ifndef VIR_DOMAIN_BLOCK_IOTUNE_TOTAL_IOPS_SEC_MAX_LENGTH
+#define VIR_DOMAIN_BLOCK_IOTUNE_TOTAL_IOPS_SEC_MAX_LENGTH "total_iops_sec_max_length"
+#endif
+
+#ifndef VIR_DOMAIN_BLOCK_IOTUNE_READ_IOPS_SEC_MAX_LENGTH
+#define VIR_DOMAIN_BLOCK_IOTUNE_READ_IOPS_SEC_MAX_LENGTH "read_iops_sec_max_length"
+#endif
+
+#ifndef VIR_DOMAIN_BLOCK_IOTUNE_WRITE_IOPS_SEC_MAX_LENGTH
+#define VIR_DOMAIN_BLOCK_IOTUNE_WRITE_IOPS_SEC_MAX_LENGTH "write_iops_sec_max_length"
+#endif
+
+#ifndef VIR_DOMAIN_BLOCK_IOTUNE_SIZE_IOPS_SEC
+#define VIR_DOMAIN_BLOCK_IOTUNE_SIZE_IOPS_SEC "size_iops_sec"
+#endif
+
+#ifndef VIR_DOMAIN_BLOCK_IOTUNE_GROUP_NAME
+#define VIR_DOMAIN_BLOCK_IOTUNE_GROUP_NAME "group_name"
+#endif
+
+#ifndef VIR_DOMAIN_BLKIO_DEVICE_READ_IOPS
+#define VIR_DOMAIN_BLKIO_DEVICE_READ_IOPS "device_read_iops_sec"
+#endif
+
+#ifndef VIR_DOMAIN_BLKIO_DEVICE_WRITE_IOPS
+#define VIR_DOMAIN_BLKIO_DEVICE_WRITE_IOPS "device_write_iops_sec"
+#endif
+
+#ifndef VIR_DOMAIN_BLKIO_DEVICE_READ_BPS
+#define VIR_DOMAIN_BLKIO_DEVICE_READ_BPS "device_read_bytes_sec"
+#endif
+
+#ifndef VIR_DOMAIN_BLKIO_DEVICE_WRITE_BPS
+#define VIR_DOMAIN_BLKIO_DEVICE_WRITE_BPS "device_write_bytes_sec"
+#endif
+
+#ifndef VIR_DOMAIN_CORE_DUMP_FORMAT_RAW
+#define VIR_DOMAIN_CORE_DUMP_FORMAT_RAW 0
+#endif
+
+#ifndef VIR_DOMAIN_CORE_DUMP_FORMAT_KDUMP_ZLIB
+#define VIR_DOMAIN_CORE_DUMP_FORMAT_KDUMP_ZLIB 1
+#endif
+
+#ifndef VIR_DOMAIN_CORE_DUMP_FORMAT_KDUMP_LZO
+#define VIR_DOMAIN_CORE_DUMP_FORMAT_KDUMP_LZO 2
+#endif
+
+#ifndef VIR_DOMAIN_CORE_DUMP_FORMAT_KDUMP_SNAPPY
+#define VIR_DOMAIN_CORE_DUMP_FORMAT_KDUMP_SNAPPY 3
+#endif
+
+#ifndef VIR_DOMAIN_CORE_DUMP_FORMAT_WIN_DMP
+#define VIR_DOMAIN_CORE_DUMP_FORMAT_WIN_DMP 4
+#endif
+
+#ifndef VIR_DOMAIN_LIFECYCLE_POWEROFF
+#define VIR_DOMAIN_LIFECYCLE_POWEROFF 0
+#endif
+
+#ifndef VIR_DOMAIN_LIFECYCLE_REBOOT
+#define VIR_DOMAIN_LIFECYCLE_REBOOT 1
+#endif
+
+#ifndef VIR_DOMAIN_LIFECYCLE_CRASH
+#define VIR_DOMAIN_LIFECYCLE_CRASH 2
+#endif
+
+#ifndef VIR_DOMAIN_LIFECYCLE_ACTION_DESTROY
+#define VIR_DOMAIN_LIFECYCLE_ACTION_DESTROY 0
+#endif
+
+#ifndef VIR_DOMAIN_LIFECYCLE_ACTION_RESTART
+#define VIR_DOMAIN_LIFECYCLE_ACTION_RESTART 1
+#endif
+
+#ifndef VIR_DOMAIN_LIFECYCLE_ACTION_RESTART_RENAME
+#define VIR_DOMAIN_LIFECYCLE_ACTION_RESTART_RENAME 2
+#endif
+
+#ifndef VIR_DOMAIN_LIFECYCLE_ACTION_PRESERVE
+#define VIR_DOMAIN_LIFECYCLE_ACTION_PRESERVE 3
+#endif
+
+#ifndef VIR_DOMAIN_LIFECYCLE_ACTION_COREDUMP_DESTROY
+#define VIR_DOMAIN_LIFECYCLE_ACTION_COREDUMP_DESTROY 4
+#endif
+
+#ifndef VIR_DOMAIN_LIFECYCLE_ACTION_COREDUMP_RESTART
+#define VIR_DOMAIN_LIFECYCLE_ACTION_COREDUMP_RESTART 5
+#endif
+
+#ifndef VIR_DOMAIN_GUEST_INFO_USERS
+#define VIR_DOMAIN_GUEST_INFO_USERS (1 << 0)
+#endif
+
+#ifndef VIR_DOMAIN_GUEST_INFO_OS
+#define VIR_DOMAIN_GUEST_INFO_OS (1 << 1)
+#endif
+
+#ifndef VIR_DOMAIN_GUEST_INFO_TIMEZONE
+#define VIR_DOMAIN_GUEST_INFO_TIMEZONE (1 << 2)
+#endif
+
+#ifndef VIR_DOMAIN_GUEST_INFO_HOSTNAME
+#define VIR_DOMAIN_GUEST_INFO_HOSTNAME (1 << 3)
+#endif
+
+#ifndef VIR_DOMAIN_GUEST_INFO_FILESYSTEM
+#define VIR_DOMAIN_GUEST_INFO_FILESYSTEM (1 << 4)
+#endif
+
+#ifndef VIR_DOMAIN_GUEST_INFO_DISKS
+#define VIR_DOMAIN_GUEST_INFO_DISKS (1 << 5)
+#endif
+
+#ifndef VIR_DOMAIN_PASSWORD_ENCRYPTED
+#define VIR_DOMAIN_PASSWORD_ENCRYPTED (1 << 0)
+#endif
+
+#ifndef VIR_DOMAIN_AUTHORIZED_SSH_KEYS_SET_APPEND
+#define VIR_DOMAIN_AUTHORIZED_SSH_KEYS_SET_APPEND (1 << 0)
+#endif
+
+#ifndef VIR_DOMAIN_AUTHORIZED_SSH_KEYS_SET_REMOVE
+#define VIR_DOMAIN_AUTHORIZED_SSH_KEYS_SET_REMOVE (1 << 1)
+#endif
+
+#ifndef VIR_DOMAIN_IOTHREAD_POLL_MAX_NS
+#define VIR_DOMAIN_IOTHREAD_POLL_MAX_NS "poll_max_ns"
+#endif
+
+#ifndef VIR_DOMAIN_IOTHREAD_POLL_GROW
+#define VIR_DOMAIN_IOTHREAD_POLL_GROW "poll_grow"
+#endif
+
+#ifndef VIR_DOMAIN_IOTHREAD_POLL_SHRINK
+#define VIR_DOMAIN_IOTHREAD_POLL_SHRINK "poll_shrink"
+#endif
+
+#ifndef VIR_DOMAIN_SAVE_IMAGE_XML_SECURE
+#define VIR_DOMAIN_SAVE_IMAGE_XML_SECURE (1 << 0)
+#endif
 */
 import "C"
 
@@ -288,9 +656,9 @@ const (
 	// event parameter in the callback is of type DomainDeviceRemovedEvent
 	VIR_DOMAIN_EVENT_ID_DEVICE_REMOVED = C.VIR_DOMAIN_EVENT_ID_DEVICE_REMOVED
 
-	// TODO Post 1.2.4, enable later
-	// event parameter in the callback is of type DomainBlockJobEvent
-	// VIR_DOMAIN_EVENT_ID_BLOCK_JOB_2 = C.VIR_DOMAIN_EVENT_ID_BLOCK_JOB_2
+	// event parameter in the callback is of type DomainBlockJobEvent,
+	// with Disk holding the target device rather than the source path
+	VIR_DOMAIN_EVENT_ID_BLOCK_JOB_2 = C.VIR_DOMAIN_EVENT_ID_BLOCK_JOB_2
 )
 
 // virDomainEventType
@@ -383,10 +751,9 @@ const (
 	// completion
 	VIR_DOMAIN_BLOCK_JOB_TYPE_COMMIT = C.VIR_DOMAIN_BLOCK_JOB_TYPE_COMMIT
 
-	// TODO Post 1.2.4, enable later
 	// Active Block Commit (virDomainBlockCommit with flags), job
 	// exists as long as sync is active
-	// VIR_DOMAIN_BLOCK_JOB_TYPE_ACTIVE_COMMIT = C.VIR_DOMAIN_BLOCK_JOB_TYPE_ACTIVE_COMMIT
+	VIR_DOMAIN_BLOCK_JOB_TYPE_ACTIVE_COMMIT = C.VIR_DOMAIN_BLOCK_JOB_TYPE_ACTIVE_COMMIT
 )
 
 // virConnectDomainEventBlockJobStatus
@@ -555,3 +922,402 @@ const (
 	VIR_DOMAIN_BLOCK_JOB_ABORT_ASYNC = C.VIR_DOMAIN_BLOCK_JOB_ABORT_ASYNC
 	VIR_DOMAIN_BLOCK_JOB_ABORT_PIVOT = C.VIR_DOMAIN_BLOCK_JOB_ABORT_PIVOT
 )
+
+type MigrationFlags uint
+
+// virDomainMigrateFlags
+const (
+	VIR_MIGRATE_LIVE              = MigrationFlags(C.VIR_MIGRATE_LIVE)              // Do not pause the domain during migration
+	VIR_MIGRATE_PEER2PEER         = MigrationFlags(C.VIR_MIGRATE_PEER2PEER)         // Source daemon connects directly to the destination
+	VIR_MIGRATE_TUNNELLED         = MigrationFlags(C.VIR_MIGRATE_TUNNELLED)         // Tunnel migration data over the libvirt RPC channel
+	VIR_MIGRATE_PERSIST_DEST      = MigrationFlags(C.VIR_MIGRATE_PERSIST_DEST)      // Persist the domain on the destination host
+	VIR_MIGRATE_UNDEFINE_SOURCE   = MigrationFlags(C.VIR_MIGRATE_UNDEFINE_SOURCE)   // Undefine the domain on the source host
+	VIR_MIGRATE_PAUSED            = MigrationFlags(C.VIR_MIGRATE_PAUSED)            // Leave the domain suspended on the destination host
+	VIR_MIGRATE_NON_SHARED_DISK   = MigrationFlags(C.VIR_MIGRATE_NON_SHARED_DISK)   // Copy full disk images
+	VIR_MIGRATE_NON_SHARED_INC    = MigrationFlags(C.VIR_MIGRATE_NON_SHARED_INC)    // Copy disk images incrementally over a shared backing file
+	VIR_MIGRATE_CHANGE_PROTECTION = MigrationFlags(C.VIR_MIGRATE_CHANGE_PROTECTION) // Protect against domain changes during migration
+	VIR_MIGRATE_UNSAFE            = MigrationFlags(C.VIR_MIGRATE_UNSAFE)            // Force migration even if it is considered unsafe
+	VIR_MIGRATE_OFFLINE           = MigrationFlags(C.VIR_MIGRATE_OFFLINE)           // Migrate the definition of an inactive domain
+	VIR_MIGRATE_COMPRESSED        = MigrationFlags(C.VIR_MIGRATE_COMPRESSED)        // Compress data during migration
+	VIR_MIGRATE_ABORT_ON_ERROR    = MigrationFlags(C.VIR_MIGRATE_ABORT_ON_ERROR)    // Abort migration on I/O errors
+	VIR_MIGRATE_AUTO_CONVERGE     = MigrationFlags(C.VIR_MIGRATE_AUTO_CONVERGE)     // Throttle guest CPUs to force convergence
+	VIR_MIGRATE_RDMA_PIN_ALL      = MigrationFlags(C.VIR_MIGRATE_RDMA_PIN_ALL)      // Pin all guest memory for RDMA migration
+	VIR_MIGRATE_POSTCOPY          = MigrationFlags(C.VIR_MIGRATE_POSTCOPY)          // Allow switching to post-copy mode
+	VIR_MIGRATE_TLS               = MigrationFlags(C.VIR_MIGRATE_TLS)               // Use TLS for the migration stream
+	VIR_MIGRATE_PARALLEL          = MigrationFlags(C.VIR_MIGRATE_PARALLEL)          // Send migration data over several connections
+)
+
+// virDomainMigrate typed parameter names
+const (
+	VIR_MIGRATE_PARAM_URI                      = C.VIR_MIGRATE_PARAM_URI
+	VIR_MIGRATE_PARAM_DEST_NAME                = C.VIR_MIGRATE_PARAM_DEST_NAME
+	VIR_MIGRATE_PARAM_DEST_XML                 = C.VIR_MIGRATE_PARAM_DEST_XML
+	VIR_MIGRATE_PARAM_PERSIST_XML              = C.VIR_MIGRATE_PARAM_PERSIST_XML
+	VIR_MIGRATE_PARAM_BANDWIDTH                = C.VIR_MIGRATE_PARAM_BANDWIDTH
+	VIR_MIGRATE_PARAM_GRAPHICS_URI             = C.VIR_MIGRATE_PARAM_GRAPHICS_URI
+	VIR_MIGRATE_PARAM_LISTEN_ADDRESS           = C.VIR_MIGRATE_PARAM_LISTEN_ADDRESS
+	VIR_MIGRATE_PARAM_MIGRATE_DISKS            = C.VIR_MIGRATE_PARAM_MIGRATE_DISKS
+	VIR_MIGRATE_PARAM_DISKS_PORT               = C.VIR_MIGRATE_PARAM_DISKS_PORT
+	VIR_MIGRATE_PARAM_COMPRESSION              = C.VIR_MIGRATE_PARAM_COMPRESSION
+	VIR_MIGRATE_PARAM_COMPRESSION_MT_LEVEL     = C.VIR_MIGRATE_PARAM_COMPRESSION_MT_LEVEL
+	VIR_MIGRATE_PARAM_COMPRESSION_MT_THREADS   = C.VIR_MIGRATE_PARAM_COMPRESSION_MT_THREADS
+	VIR_MIGRATE_PARAM_COMPRESSION_MT_DTHREADS  = C.VIR_MIGRATE_PARAM_COMPRESSION_MT_DTHREADS
+	VIR_MIGRATE_PARAM_COMPRESSION_XBZRLE_CACHE = C.VIR_MIGRATE_PARAM_COMPRESSION_XBZRLE_CACHE
+	VIR_MIGRATE_PARAM_AUTO_CONVERGE_INITIAL    = C.VIR_MIGRATE_PARAM_AUTO_CONVERGE_INITIAL
+	VIR_MIGRATE_PARAM_AUTO_CONVERGE_INCREMENT  = C.VIR_MIGRATE_PARAM_AUTO_CONVERGE_INCREMENT
+	VIR_MIGRATE_PARAM_PARALLEL_CONNECTIONS     = C.VIR_MIGRATE_PARAM_PARALLEL_CONNECTIONS
+)
+
+// virDomainJobType
+const (
+	VIR_DOMAIN_JOB_NONE      = C.VIR_DOMAIN_JOB_NONE      // No job is active
+	VIR_DOMAIN_JOB_BOUNDED   = C.VIR_DOMAIN_JOB_BOUNDED   // Job with a finite completion time
+	VIR_DOMAIN_JOB_UNBOUNDED = C.VIR_DOMAIN_JOB_UNBOUNDED // Job without a finite completion time
+	VIR_DOMAIN_JOB_COMPLETED = C.VIR_DOMAIN_JOB_COMPLETED // Job has finished, but isn't cleaned up
+	VIR_DOMAIN_JOB_FAILED    = C.VIR_DOMAIN_JOB_FAILED    // Job hit error, but isn't cleaned up
+	VIR_DOMAIN_JOB_CANCELLED = C.VIR_DOMAIN_JOB_CANCELLED // Job was aborted, but isn't cleaned up
+)
+
+// virDomainGetJobStatsFlags
+const (
+	VIR_DOMAIN_JOB_STATS_COMPLETED = C.VIR_DOMAIN_JOB_STATS_COMPLETED // Return stats of a recently completed job
+)
+
+// virDomainJobOperation
+const (
+	VIR_DOMAIN_JOB_OPERATION_UNKNOWN         = C.VIR_DOMAIN_JOB_OPERATION_UNKNOWN
+	VIR_DOMAIN_JOB_OPERATION_START           = C.VIR_DOMAIN_JOB_OPERATION_START
+	VIR_DOMAIN_JOB_OPERATION_SAVE            = C.VIR_DOMAIN_JOB_OPERATION_SAVE
+	VIR_DOMAIN_JOB_OPERATION_RESTORE         = C.VIR_DOMAIN_JOB_OPERATION_RESTORE
+	VIR_DOMAIN_JOB_OPERATION_MIGRATION_IN    = C.VIR_DOMAIN_JOB_OPERATION_MIGRATION_IN
+	VIR_DOMAIN_JOB_OPERATION_MIGRATION_OUT   = C.VIR_DOMAIN_JOB_OPERATION_MIGRATION_OUT
+	VIR_DOMAIN_JOB_OPERATION_SNAPSHOT        = C.VIR_DOMAIN_JOB_OPERATION_SNAPSHOT
+	VIR_DOMAIN_JOB_OPERATION_SNAPSHOT_REVERT = C.VIR_DOMAIN_JOB_OPERATION_SNAPSHOT_REVERT
+	VIR_DOMAIN_JOB_OPERATION_DUMP            = C.VIR_DOMAIN_JOB_OPERATION_DUMP
+)
+
+// virDomainGetJobStats typed parameter names
+const (
+	VIR_DOMAIN_JOB_OPERATION                = C.VIR_DOMAIN_JOB_OPERATION
+	VIR_DOMAIN_JOB_TIME_ELAPSED             = C.VIR_DOMAIN_JOB_TIME_ELAPSED
+	VIR_DOMAIN_JOB_TIME_ELAPSED_NET         = C.VIR_DOMAIN_JOB_TIME_ELAPSED_NET
+	VIR_DOMAIN_JOB_TIME_REMAINING           = C.VIR_DOMAIN_JOB_TIME_REMAINING
+	VIR_DOMAIN_JOB_DOWNTIME                 = C.VIR_DOMAIN_JOB_DOWNTIME
+	VIR_DOMAIN_JOB_DOWNTIME_NET             = C.VIR_DOMAIN_JOB_DOWNTIME_NET
+	VIR_DOMAIN_JOB_SETUP_TIME               = C.VIR_DOMAIN_JOB_SETUP_TIME
+	VIR_DOMAIN_JOB_DATA_TOTAL               = C.VIR_DOMAIN_JOB_DATA_TOTAL
+	VIR_DOMAIN_JOB_DATA_PROCESSED           = C.VIR_DOMAIN_JOB_DATA_PROCESSED
+	VIR_DOMAIN_JOB_DATA_REMAINING           = C.VIR_DOMAIN_JOB_DATA_REMAINING
+	VIR_DOMAIN_JOB_MEMORY_TOTAL             = C.VIR_DOMAIN_JOB_MEMORY_TOTAL
+	VIR_DOMAIN_JOB_MEMORY_PROCESSED         = C.VIR_DOMAIN_JOB_MEMORY_PROCESSED
+	VIR_DOMAIN_JOB_MEMORY_REMAINING         = C.VIR_DOMAIN_JOB_MEMORY_REMAINING
+	VIR_DOMAIN_JOB_MEMORY_CONSTANT          = C.VIR_DOMAIN_JOB_MEMORY_CONSTANT
+	VIR_DOMAIN_JOB_MEMORY_NORMAL            = C.VIR_DOMAIN_JOB_MEMORY_NORMAL
+	VIR_DOMAIN_JOB_MEMORY_NORMAL_BYTES      = C.VIR_DOMAIN_JOB_MEMORY_NORMAL_BYTES
+	VIR_DOMAIN_JOB_MEMORY_BPS               = C.VIR_DOMAIN_JOB_MEMORY_BPS
+	VIR_DOMAIN_JOB_MEMORY_DIRTY_RATE        = C.VIR_DOMAIN_JOB_MEMORY_DIRTY_RATE
+	VIR_DOMAIN_JOB_MEMORY_PAGE_SIZE         = C.VIR_DOMAIN_JOB_MEMORY_PAGE_SIZE
+	VIR_DOMAIN_JOB_MEMORY_ITERATION         = C.VIR_DOMAIN_JOB_MEMORY_ITERATION
+	VIR_DOMAIN_JOB_DISK_TOTAL               = C.VIR_DOMAIN_JOB_DISK_TOTAL
+	VIR_DOMAIN_JOB_DISK_PROCESSED           = C.VIR_DOMAIN_JOB_DISK_PROCESSED
+	VIR_DOMAIN_JOB_DISK_REMAINING           = C.VIR_DOMAIN_JOB_DISK_REMAINING
+	VIR_DOMAIN_JOB_DISK_BPS                 = C.VIR_DOMAIN_JOB_DISK_BPS
+	VIR_DOMAIN_JOB_COMPRESSION_CACHE        = C.VIR_DOMAIN_JOB_COMPRESSION_CACHE
+	VIR_DOMAIN_JOB_COMPRESSION_BYTES        = C.VIR_DOMAIN_JOB_COMPRESSION_BYTES
+	VIR_DOMAIN_JOB_COMPRESSION_PAGES        = C.VIR_DOMAIN_JOB_COMPRESSION_PAGES
+	VIR_DOMAIN_JOB_COMPRESSION_CACHE_MISSES = C.VIR_DOMAIN_JOB_COMPRESSION_CACHE_MISSES
+	VIR_DOMAIN_JOB_COMPRESSION_OVERFLOW     = C.VIR_DOMAIN_JOB_COMPRESSION_OVERFLOW
+	VIR_DOMAIN_JOB_AUTO_CONVERGE_THROTTLE   = C.VIR_DOMAIN_JOB_AUTO_CONVERGE_THROTTLE
+)
+
+// virDomainSnapshotCreateFlags
+const (
+	VIR_DOMAIN_SNAPSHOT_CREATE_REDEFINE    = C.VIR_DOMAIN_SNAPSHOT_CREATE_REDEFINE    // Restore or alter metadata
+	VIR_DOMAIN_SNAPSHOT_CREATE_CURRENT     = C.VIR_DOMAIN_SNAPSHOT_CREATE_CURRENT     // With redefine, make snapshot current
+	VIR_DOMAIN_SNAPSHOT_CREATE_NO_METADATA = C.VIR_DOMAIN_SNAPSHOT_CREATE_NO_METADATA // Make snapshot without remembering it
+	VIR_DOMAIN_SNAPSHOT_CREATE_HALT        = C.VIR_DOMAIN_SNAPSHOT_CREATE_HALT        // Stop running guest after snapshot
+	VIR_DOMAIN_SNAPSHOT_CREATE_DISK_ONLY   = C.VIR_DOMAIN_SNAPSHOT_CREATE_DISK_ONLY   // Disk snapshot, not system checkpoint
+	VIR_DOMAIN_SNAPSHOT_CREATE_REUSE_EXT   = C.VIR_DOMAIN_SNAPSHOT_CREATE_REUSE_EXT   // Reuse any existing external files
+	VIR_DOMAIN_SNAPSHOT_CREATE_QUIESCE     = C.VIR_DOMAIN_SNAPSHOT_CREATE_QUIESCE     // Use guest agent to quiesce all mounted file systems
+	VIR_DOMAIN_SNAPSHOT_CREATE_ATOMIC      = C.VIR_DOMAIN_SNAPSHOT_CREATE_ATOMIC      // Atomically avoid partial changes
+	VIR_DOMAIN_SNAPSHOT_CREATE_LIVE        = C.VIR_DOMAIN_SNAPSHOT_CREATE_LIVE        // Create the snapshot while the guest is running
+)
+
+// virDomainSnapshotListFlags
+const (
+	VIR_DOMAIN_SNAPSHOT_LIST_ROOTS       = C.VIR_DOMAIN_SNAPSHOT_LIST_ROOTS       // Filter by snapshots with no parents, when listing a domain
+	VIR_DOMAIN_SNAPSHOT_LIST_DESCENDANTS = C.VIR_DOMAIN_SNAPSHOT_LIST_DESCENDANTS // List all descendants, not just children, when listing a snapshot
+	VIR_DOMAIN_SNAPSHOT_LIST_LEAVES      = C.VIR_DOMAIN_SNAPSHOT_LIST_LEAVES      // Filter by snapshots with no children
+	VIR_DOMAIN_SNAPSHOT_LIST_NO_LEAVES   = C.VIR_DOMAIN_SNAPSHOT_LIST_NO_LEAVES   // Filter by snapshots that have children
+	VIR_DOMAIN_SNAPSHOT_LIST_METADATA    = C.VIR_DOMAIN_SNAPSHOT_LIST_METADATA    // Filter by snapshots which have metadata
+	VIR_DOMAIN_SNAPSHOT_LIST_NO_METADATA = C.VIR_DOMAIN_SNAPSHOT_LIST_NO_METADATA // Filter by snapshots with no metadata
+	VIR_DOMAIN_SNAPSHOT_LIST_INACTIVE    = C.VIR_DOMAIN_SNAPSHOT_LIST_INACTIVE    // Filter by snapshots taken while guest was shut off
+	VIR_DOMAIN_SNAPSHOT_LIST_ACTIVE      = C.VIR_DOMAIN_SNAPSHOT_LIST_ACTIVE      // Filter by snapshots taken while guest was active, and with memory state
+	VIR_DOMAIN_SNAPSHOT_LIST_DISK_ONLY   = C.VIR_DOMAIN_SNAPSHOT_LIST_DISK_ONLY   // Filter by snapshots taken while guest was active, but without memory state
+	VIR_DOMAIN_SNAPSHOT_LIST_INTERNAL    = C.VIR_DOMAIN_SNAPSHOT_LIST_INTERNAL    // Filter by snapshots stored internal to disk images
+	VIR_DOMAIN_SNAPSHOT_LIST_EXTERNAL    = C.VIR_DOMAIN_SNAPSHOT_LIST_EXTERNAL    // Filter by snapshots that use files external to disk images
+)
+
+// virDomainSnapshotRevertFlags
+const (
+	VIR_DOMAIN_SNAPSHOT_REVERT_RUNNING = C.VIR_DOMAIN_SNAPSHOT_REVERT_RUNNING // Run after revert
+	VIR_DOMAIN_SNAPSHOT_REVERT_PAUSED  = C.VIR_DOMAIN_SNAPSHOT_REVERT_PAUSED  // Pause after revert
+	VIR_DOMAIN_SNAPSHOT_REVERT_FORCE   = C.VIR_DOMAIN_SNAPSHOT_REVERT_FORCE   // Allow risky reverts
+)
+
+// virDomainSnapshotDeleteFlags
+const (
+	VIR_DOMAIN_SNAPSHOT_DELETE_CHILDREN      = C.VIR_DOMAIN_SNAPSHOT_DELETE_CHILDREN      // Also delete children
+	VIR_DOMAIN_SNAPSHOT_DELETE_METADATA_ONLY = C.VIR_DOMAIN_SNAPSHOT_DELETE_METADATA_ONLY // Delete just metadata
+	VIR_DOMAIN_SNAPSHOT_DELETE_CHILDREN_ONLY = C.VIR_DOMAIN_SNAPSHOT_DELETE_CHILDREN_ONLY // Delete just children
+)
+
+type BlockCommitFlags uint32
+
+// virDomainBlockCommitFlags
+const (
+	VIR_DOMAIN_BLOCK_COMMIT_SHALLOW         = BlockCommitFlags(C.VIR_DOMAIN_BLOCK_COMMIT_SHALLOW)         // Only commit the top image into its backing file
+	VIR_DOMAIN_BLOCK_COMMIT_DELETE          = BlockCommitFlags(C.VIR_DOMAIN_BLOCK_COMMIT_DELETE)          // Delete the committed files on success
+	VIR_DOMAIN_BLOCK_COMMIT_ACTIVE          = BlockCommitFlags(C.VIR_DOMAIN_BLOCK_COMMIT_ACTIVE)          // Allow a two-phase commit when top is the active layer
+	VIR_DOMAIN_BLOCK_COMMIT_RELATIVE        = BlockCommitFlags(C.VIR_DOMAIN_BLOCK_COMMIT_RELATIVE)        // Keep the backing chain referenced using relative names
+	VIR_DOMAIN_BLOCK_COMMIT_BANDWIDTH_BYTES = BlockCommitFlags(C.VIR_DOMAIN_BLOCK_COMMIT_BANDWIDTH_BYTES) // Bandwidth is in bytes/s instead of MiB/s
+)
+
+type BlockPullFlags uint32
+
+// virDomainBlockPullFlags
+const (
+	VIR_DOMAIN_BLOCK_PULL_BANDWIDTH_BYTES = BlockPullFlags(C.VIR_DOMAIN_BLOCK_PULL_BANDWIDTH_BYTES) // Bandwidth is in bytes/s instead of MiB/s
+)
+
+type BlockRebaseFlags uint32
+
+// virDomainBlockRebaseFlags
+const (
+	VIR_DOMAIN_BLOCK_REBASE_SHALLOW         = BlockRebaseFlags(C.VIR_DOMAIN_BLOCK_REBASE_SHALLOW)         // Limit copy to top of source backing chain
+	VIR_DOMAIN_BLOCK_REBASE_REUSE_EXT       = BlockRebaseFlags(C.VIR_DOMAIN_BLOCK_REBASE_REUSE_EXT)       // Reuse existing external file for a copy
+	VIR_DOMAIN_BLOCK_REBASE_COPY_RAW        = BlockRebaseFlags(C.VIR_DOMAIN_BLOCK_REBASE_COPY_RAW)        // Make destination file raw
+	VIR_DOMAIN_BLOCK_REBASE_COPY            = BlockRebaseFlags(C.VIR_DOMAIN_BLOCK_REBASE_COPY)            // Start a copy job
+	VIR_DOMAIN_BLOCK_REBASE_RELATIVE        = BlockRebaseFlags(C.VIR_DOMAIN_BLOCK_REBASE_RELATIVE)        // Keep backing chain referenced using relative names
+	VIR_DOMAIN_BLOCK_REBASE_COPY_DEV        = BlockRebaseFlags(C.VIR_DOMAIN_BLOCK_REBASE_COPY_DEV)        // Treat destination as block device instead of file
+	VIR_DOMAIN_BLOCK_REBASE_BANDWIDTH_BYTES = BlockRebaseFlags(C.VIR_DOMAIN_BLOCK_REBASE_BANDWIDTH_BYTES) // Bandwidth is in bytes/s instead of MiB/s
+)
+
+type BlockJobSetSpeedFlags uint32
+
+// virDomainBlockJobSetSpeedFlags
+const (
+	VIR_DOMAIN_BLOCK_JOB_SPEED_BANDWIDTH_BYTES = BlockJobSetSpeedFlags(C.VIR_DOMAIN_BLOCK_JOB_SPEED_BANDWIDTH_BYTES) // Bandwidth is in bytes/s instead of MiB/s
+)
+
+type BlockResizeFlags uint32
+
+// virDomainBlockResizeFlags
+const (
+	VIR_DOMAIN_BLOCK_RESIZE_BYTES = BlockResizeFlags(C.VIR_DOMAIN_BLOCK_RESIZE_BYTES) // Size is in bytes instead of KiB
+)
+
+// virDomainSetBlockIoTune typed parameter names
+const (
+	VIR_DOMAIN_BLOCK_IOTUNE_TOTAL_BYTES_SEC            = C.VIR_DOMAIN_BLOCK_IOTUNE_TOTAL_BYTES_SEC
+	VIR_DOMAIN_BLOCK_IOTUNE_READ_BYTES_SEC             = C.VIR_DOMAIN_BLOCK_IOTUNE_READ_BYTES_SEC
+	VIR_DOMAIN_BLOCK_IOTUNE_WRITE_BYTES_SEC            = C.VIR_DOMAIN_BLOCK_IOTUNE_WRITE_BYTES_SEC
+	VIR_DOMAIN_BLOCK_IOTUNE_TOTAL_IOPS_SEC             = C.VIR_DOMAIN_BLOCK_IOTUNE_TOTAL_IOPS_SEC
+	VIR_DOMAIN_BLOCK_IOTUNE_READ_IOPS_SEC              = C.VIR_DOMAIN_BLOCK_IOTUNE_READ_IOPS_SEC
+	VIR_DOMAIN_BLOCK_IOTUNE_WRITE_IOPS_SEC             = C.VIR_DOMAIN_BLOCK_IOTUNE_WRITE_IOPS_SEC
+	VIR_DOMAIN_BLOCK_IOTUNE_TOTAL_BYTES_SEC_MAX        = C.VIR_DOMAIN_BLOCK_IOTUNE_TOTAL_BYTES_SEC_MAX
+	VIR_DOMAIN_BLOCK_IOTUNE_READ_BYTES_SEC_MAX         = C.VIR_DOMAIN_BLOCK_IOTUNE_READ_BYTES_SEC_MAX
+	VIR_DOMAIN_BLOCK_IOTUNE_WRITE_BYTES_SEC_MAX        = C.VIR_DOMAIN_BLOCK_IOTUNE_WRITE_BYTES_SEC_MAX
+	VIR_DOMAIN_BLOCK_IOTUNE_TOTAL_IOPS_SEC_MAX         = C.VIR_DOMAIN_BLOCK_IOTUNE_TOTAL_IOPS_SEC_MAX
+	VIR_DOMAIN_BLOCK_IOTUNE_READ_IOPS_SEC_MAX          = C.VIR_DOMAIN_BLOCK_IOTUNE_READ_IOPS_SEC_MAX
+	VIR_DOMAIN_BLOCK_IOTUNE_WRITE_IOPS_SEC_MAX         = C.VIR_DOMAIN_BLOCK_IOTUNE_WRITE_IOPS_SEC_MAX
+	VIR_DOMAIN_BLOCK_IOTUNE_TOTAL_BYTES_SEC_MAX_LENGTH = C.VIR_DOMAIN_BLOCK_IOTUNE_TOTAL_BYTES_SEC_MAX_LENGTH
+	VIR_DOMAIN_BLOCK_IOTUNE_READ_BYTES_SEC_MAX_LENGTH  = C.VIR_DOMAIN_BLOCK_IOTUNE_READ_BYTES_SEC_MAX_LENGTH
+	VIR_DOMAIN_BLOCK_IOTUNE_WRITE_BYTES_SEC_MAX_LENGTH = C.VIR_DOMAIN_BLOCK_IOTUNE_WRITE_BYTES_SEC_MAX_LENGTH
+	VIR_DOMAIN_BLOCK_IOTUNE_TOTAL_IOPS_SEC_MAX_LENGTH  = C.VIR_DOMAIN_BLOCK_IOTUNE_TOTAL_IOPS_SEC_MAX_LENGTH
+	VIR_DOMAIN_BLOCK_IOTUNE_READ_IOPS_SEC_MAX_LENGTH   = C.VIR_DOMAIN_BLOCK_IOTUNE_READ_IOPS_SEC_MAX_LENGTH
+	VIR_DOMAIN_BLOCK_IOTUNE_WRITE_IOPS_SEC_MAX_LENGTH  = C.VIR_DOMAIN_BLOCK_IOTUNE_WRITE_IOPS_SEC_MAX_LENGTH
+	VIR_DOMAIN_BLOCK_IOTUNE_SIZE_IOPS_SEC              = C.VIR_DOMAIN_BLOCK_IOTUNE_SIZE_IOPS_SEC
+	VIR_DOMAIN_BLOCK_IOTUNE_GROUP_NAME                 = C.VIR_DOMAIN_BLOCK_IOTUNE_GROUP_NAME
+)
+
+// virDomainSetSchedulerParameters typed parameter names
+const (
+	VIR_DOMAIN_SCHEDULER_CPU_SHARES      = C.VIR_DOMAIN_SCHEDULER_CPU_SHARES
+	VIR_DOMAIN_SCHEDULER_VCPU_PERIOD     = C.VIR_DOMAIN_SCHEDULER_VCPU_PERIOD
+	VIR_DOMAIN_SCHEDULER_VCPU_QUOTA      = C.VIR_DOMAIN_SCHEDULER_VCPU_QUOTA
+	VIR_DOMAIN_SCHEDULER_EMULATOR_PERIOD = C.VIR_DOMAIN_SCHEDULER_EMULATOR_PERIOD
+	VIR_DOMAIN_SCHEDULER_EMULATOR_QUOTA  = C.VIR_DOMAIN_SCHEDULER_EMULATOR_QUOTA
+)
+
+// virDomainSetMemoryParameters typed parameter names
+const (
+	VIR_DOMAIN_MEMORY_HARD_LIMIT      = C.VIR_DOMAIN_MEMORY_HARD_LIMIT
+	VIR_DOMAIN_MEMORY_SOFT_LIMIT      = C.VIR_DOMAIN_MEMORY_SOFT_LIMIT
+	VIR_DOMAIN_MEMORY_MIN_GUARANTEE   = C.VIR_DOMAIN_MEMORY_MIN_GUARANTEE
+	VIR_DOMAIN_MEMORY_SWAP_HARD_LIMIT = C.VIR_DOMAIN_MEMORY_SWAP_HARD_LIMIT
+)
+
+// virDomainSetBlkioParameters typed parameter names
+const (
+	VIR_DOMAIN_BLKIO_WEIGHT            = C.VIR_DOMAIN_BLKIO_WEIGHT
+	VIR_DOMAIN_BLKIO_DEVICE_WEIGHT     = C.VIR_DOMAIN_BLKIO_DEVICE_WEIGHT
+	VIR_DOMAIN_BLKIO_DEVICE_READ_IOPS  = C.VIR_DOMAIN_BLKIO_DEVICE_READ_IOPS
+	VIR_DOMAIN_BLKIO_DEVICE_WRITE_IOPS = C.VIR_DOMAIN_BLKIO_DEVICE_WRITE_IOPS
+	VIR_DOMAIN_BLKIO_DEVICE_READ_BPS   = C.VIR_DOMAIN_BLKIO_DEVICE_READ_BPS
+	VIR_DOMAIN_BLKIO_DEVICE_WRITE_BPS  = C.VIR_DOMAIN_BLKIO_DEVICE_WRITE_BPS
+)
+
+// virDomainSetNumaParameters typed parameter names
+const (
+	VIR_DOMAIN_NUMA_NODESET = C.VIR_DOMAIN_NUMA_NODESET
+	VIR_DOMAIN_NUMA_MODE    = C.VIR_DOMAIN_NUMA_MODE
+)
+
+// virDomainNumatuneMemMode
+const (
+	VIR_DOMAIN_NUMATUNE_MEM_STRICT     = C.VIR_DOMAIN_NUMATUNE_MEM_STRICT
+	VIR_DOMAIN_NUMATUNE_MEM_PREFERRED  = C.VIR_DOMAIN_NUMATUNE_MEM_PREFERRED
+	VIR_DOMAIN_NUMATUNE_MEM_INTERLEAVE = C.VIR_DOMAIN_NUMATUNE_MEM_INTERLEAVE
+)
+
+// virDomainSetInterfaceParameters typed parameter names
+const (
+	VIR_DOMAIN_BANDWIDTH_IN_AVERAGE  = C.VIR_DOMAIN_BANDWIDTH_IN_AVERAGE
+	VIR_DOMAIN_BANDWIDTH_IN_PEAK     = C.VIR_DOMAIN_BANDWIDTH_IN_PEAK
+	VIR_DOMAIN_BANDWIDTH_IN_BURST    = C.VIR_DOMAIN_BANDWIDTH_IN_BURST
+	VIR_DOMAIN_BANDWIDTH_IN_FLOOR    = C.VIR_DOMAIN_BANDWIDTH_IN_FLOOR
+	VIR_DOMAIN_BANDWIDTH_OUT_AVERAGE = C.VIR_DOMAIN_BANDWIDTH_OUT_AVERAGE
+	VIR_DOMAIN_BANDWIDTH_OUT_PEAK    = C.VIR_DOMAIN_BANDWIDTH_OUT_PEAK
+	VIR_DOMAIN_BANDWIDTH_OUT_BURST   = C.VIR_DOMAIN_BANDWIDTH_OUT_BURST
+)
+
+// virStreamEventType
+const (
+	VIR_STREAM_EVENT_READABLE = C.VIR_STREAM_EVENT_READABLE
+	VIR_STREAM_EVENT_WRITABLE = C.VIR_STREAM_EVENT_WRITABLE
+	VIR_STREAM_EVENT_ERROR    = C.VIR_STREAM_EVENT_ERROR
+	VIR_STREAM_EVENT_HANGUP   = C.VIR_STREAM_EVENT_HANGUP
+)
+
+// virDomainConsoleFlags
+const (
+	VIR_DOMAIN_CONSOLE_FORCE = C.VIR_DOMAIN_CONSOLE_FORCE
+	VIR_DOMAIN_CONSOLE_SAFE  = C.VIR_DOMAIN_CONSOLE_SAFE
+)
+
+// virDomainOpenGraphicsFlags
+const (
+	VIR_DOMAIN_OPEN_GRAPHICS_SKIPAUTH = C.VIR_DOMAIN_OPEN_GRAPHICS_SKIPAUTH
+)
+
+// virDomainChannelFlags
+const (
+	VIR_DOMAIN_CHANNEL_FORCE = C.VIR_DOMAIN_CHANNEL_FORCE
+)
+
+type DomainCoreDumpFlags uint
+
+// virDomainCoreDumpFlags
+const (
+	VIR_DUMP_CRASH        = DomainCoreDumpFlags(C.VIR_DUMP_CRASH)        // Crash the domain after the dump
+	VIR_DUMP_LIVE         = DomainCoreDumpFlags(C.VIR_DUMP_LIVE)         // Keep the domain running during the dump
+	VIR_DUMP_BYPASS_CACHE = DomainCoreDumpFlags(C.VIR_DUMP_BYPASS_CACHE) // Avoid the file system cache
+	VIR_DUMP_RESET        = DomainCoreDumpFlags(C.VIR_DUMP_RESET)        // Reset the domain after the dump
+	VIR_DUMP_MEMORY_ONLY  = DomainCoreDumpFlags(C.VIR_DUMP_MEMORY_ONLY)  // Dump guest memory only, in ELF format
+)
+
+type DomainCoreDumpFormat uint
+
+// virDomainCoreDumpFormat
+const (
+	VIR_DOMAIN_CORE_DUMP_FORMAT_RAW          = DomainCoreDumpFormat(C.VIR_DOMAIN_CORE_DUMP_FORMAT_RAW)
+	VIR_DOMAIN_CORE_DUMP_FORMAT_KDUMP_ZLIB   = DomainCoreDumpFormat(C.VIR_DOMAIN_CORE_DUMP_FORMAT_KDUMP_ZLIB)
+	VIR_DOMAIN_CORE_DUMP_FORMAT_KDUMP_LZO    = DomainCoreDumpFormat(C.VIR_DOMAIN_CORE_DUMP_FORMAT_KDUMP_LZO)
+	VIR_DOMAIN_CORE_DUMP_FORMAT_KDUMP_SNAPPY = DomainCoreDumpFormat(C.VIR_DOMAIN_CORE_DUMP_FORMAT_KDUMP_SNAPPY)
+	VIR_DOMAIN_CORE_DUMP_FORMAT_WIN_DMP      = DomainCoreDumpFormat(C.VIR_DOMAIN_CORE_DUMP_FORMAT_WIN_DMP)
+)
+
+type DomainMemoryFlags uint
+
+// virDomainMemoryFlags
+const (
+	VIR_MEMORY_VIRTUAL  = DomainMemoryFlags(C.VIR_MEMORY_VIRTUAL)  // Addresses are guest virtual
+	VIR_MEMORY_PHYSICAL = DomainMemoryFlags(C.VIR_MEMORY_PHYSICAL) // Addresses are guest physical
+)
+
+// virDomainSaveRestoreFlags
+const (
+	VIR_DOMAIN_SAVE_BYPASS_CACHE = C.VIR_DOMAIN_SAVE_BYPASS_CACHE // Avoid the file system cache
+	VIR_DOMAIN_SAVE_RUNNING      = C.VIR_DOMAIN_SAVE_RUNNING      // Restore the domain running
+	VIR_DOMAIN_SAVE_PAUSED       = C.VIR_DOMAIN_SAVE_PAUSED       // Restore the domain paused
+)
+
+// virDomainSaveImageXMLFlags
+const (
+	VIR_DOMAIN_SAVE_IMAGE_XML_SECURE = C.VIR_DOMAIN_SAVE_IMAGE_XML_SECURE // Include security sensitive information
+)
+
+type NodeSuspendTarget uint
+
+// virNodeSuspendTarget
+const (
+	VIR_NODE_SUSPEND_TARGET_MEM    = NodeSuspendTarget(C.VIR_NODE_SUSPEND_TARGET_MEM)    // Suspend to RAM (S3)
+	VIR_NODE_SUSPEND_TARGET_DISK   = NodeSuspendTarget(C.VIR_NODE_SUSPEND_TARGET_DISK)   // Suspend to disk (S4)
+	VIR_NODE_SUSPEND_TARGET_HYBRID = NodeSuspendTarget(C.VIR_NODE_SUSPEND_TARGET_HYBRID) // Suspend to both
+)
+
+type DomainLifecycle uint
+
+// virDomainLifecycle
+const (
+	VIR_DOMAIN_LIFECYCLE_POWEROFF = DomainLifecycle(C.VIR_DOMAIN_LIFECYCLE_POWEROFF) // <on_poweroff>
+	VIR_DOMAIN_LIFECYCLE_REBOOT   = DomainLifecycle(C.VIR_DOMAIN_LIFECYCLE_REBOOT)   // <on_reboot>
+	VIR_DOMAIN_LIFECYCLE_CRASH    = DomainLifecycle(C.VIR_DOMAIN_LIFECYCLE_CRASH)    // <on_crash>
+)
+
+type DomainLifecycleAction uint
+
+// virDomainLifecycleAction
+const (
+	VIR_DOMAIN_LIFECYCLE_ACTION_DESTROY          = DomainLifecycleAction(C.VIR_DOMAIN_LIFECYCLE_ACTION_DESTROY)          // Power off
+	VIR_DOMAIN_LIFECYCLE_ACTION_RESTART          = DomainLifecycleAction(C.VIR_DOMAIN_LIFECYCLE_ACTION_RESTART)          // Start again
+	VIR_DOMAIN_LIFECYCLE_ACTION_RESTART_RENAME   = DomainLifecycleAction(C.VIR_DOMAIN_LIFECYCLE_ACTION_RESTART_RENAME)   // Start again under a new name
+	VIR_DOMAIN_LIFECYCLE_ACTION_PRESERVE         = DomainLifecycleAction(C.VIR_DOMAIN_LIFECYCLE_ACTION_PRESERVE)         // Keep the domain in its state
+	VIR_DOMAIN_LIFECYCLE_ACTION_COREDUMP_DESTROY = DomainLifecycleAction(C.VIR_DOMAIN_LIFECYCLE_ACTION_COREDUMP_DESTROY) // Dump core, then power off
+	VIR_DOMAIN_LIFECYCLE_ACTION_COREDUMP_RESTART = DomainLifecycleAction(C.VIR_DOMAIN_LIFECYCLE_ACTION_COREDUMP_RESTART) // Dump core, then start again
+)
+
+type DomainGuestInfoTypes uint
+
+// virDomainGuestInfoTypes
+const (
+	VIR_DOMAIN_GUEST_INFO_USERS      = DomainGuestInfoTypes(C.VIR_DOMAIN_GUEST_INFO_USERS)      // Logged in users
+	VIR_DOMAIN_GUEST_INFO_OS         = DomainGuestInfoTypes(C.VIR_DOMAIN_GUEST_INFO_OS)         // Operating system
+	VIR_DOMAIN_GUEST_INFO_TIMEZONE   = DomainGuestInfoTypes(C.VIR_DOMAIN_GUEST_INFO_TIMEZONE)   // Time zone
+	VIR_DOMAIN_GUEST_INFO_HOSTNAME   = DomainGuestInfoTypes(C.VIR_DOMAIN_GUEST_INFO_HOSTNAME)   // Hostname
+	VIR_DOMAIN_GUEST_INFO_FILESYSTEM = DomainGuestInfoTypes(C.VIR_DOMAIN_GUEST_INFO_FILESYSTEM) // Mounted file systems
+	VIR_DOMAIN_GUEST_INFO_DISKS      = DomainGuestInfoTypes(C.VIR_DOMAIN_GUEST_INFO_DISKS)      // Block devices
+)
+
+type DomainSetUserPasswordFlags uint
+
+// virDomainSetUserPasswordFlags
+const (
+	VIR_DOMAIN_PASSWORD_ENCRYPTED = DomainSetUserPasswordFlags(C.VIR_DOMAIN_PASSWORD_ENCRYPTED) // The password is already encrypted, as by crypt(3)
+)
+
+type DomainAuthorizedSSHKeysSetFlags uint
+
+// virDomainAuthorizedSSHKeysSetFlags
+const (
+	VIR_DOMAIN_AUTHORIZED_SSH_KEYS_SET_APPEND = DomainAuthorizedSSHKeysSetFlags(C.VIR_DOMAIN_AUTHORIZED_SSH_KEYS_SET_APPEND) // Append keys instead of replacing the file
+	VIR_DOMAIN_AUTHORIZED_SSH_KEYS_SET_REMOVE = DomainAuthorizedSSHKeysSetFlags(C.VIR_DOMAIN_AUTHORIZED_SSH_KEYS_SET_REMOVE) // Remove keys instead of adding them
+)
+
+// virDomainSetIOThreadParams typed parameter names
+const (
+	VIR_DOMAIN_IOTHREAD_POLL_MAX_NS = C.VIR_DOMAIN_IOTHREAD_POLL_MAX_NS
+	VIR_DOMAIN_IOTHREAD_POLL_GROW   = C.VIR_DOMAIN_IOTHREAD_POLL_GROW
+	VIR_DOMAIN_IOTHREAD_POLL_SHRINK = C.VIR_DOMAIN_IOTHREAD_POLL_SHRINK
+)
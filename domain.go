@@ -10,6 +10,7 @@ package libvirt
 import "C"
 
 import (
+	"errors"
 	"reflect"
 	"strings"
 	"unsafe"
@@ -88,6 +89,209 @@ func (dest *VirTypedParameters) loadFromCPtr(params C.virTypedParameterPtr, nPar
 	}
 }
 
+func (dest *VirTypedParameters) loadToCPtr() (params C.virTypedParameterPtr, nParams C.int, err error) {
+	var maxParams C.int = 0
+	params = nil
+
+	for _, param := range *dest {
+		cName := C.CString(param.Name)
+		defer C.free(unsafe.Pointer(cName))
+
+		var result C.int
+		switch value := param.Value.(type) {
+		case int:
+			result = C.virTypedParamsAddInt(&params, &nParams, &maxParams, cName, C.int(value))
+		case int32:
+			result = C.virTypedParamsAddInt(&params, &nParams, &maxParams, cName, C.int(value))
+		case uint:
+			result = C.virTypedParamsAddUInt(&params, &nParams, &maxParams, cName, C.uint(value))
+		case uint32:
+			result = C.virTypedParamsAddUInt(&params, &nParams, &maxParams, cName, C.uint(value))
+		case int64:
+			result = C.virTypedParamsAddLLong(&params, &nParams, &maxParams, cName, C.longlong(value))
+		case uint64:
+			result = C.virTypedParamsAddULLong(&params, &nParams, &maxParams, cName, C.ulonglong(value))
+		case float64:
+			result = C.virTypedParamsAddDouble(&params, &nParams, &maxParams, cName, C.double(value))
+		case bool:
+			var cValue C.int
+			if value {
+				cValue = 1
+			}
+			result = C.virTypedParamsAddBoolean(&params, &nParams, &maxParams, cName, cValue)
+		case string:
+			cValue := C.CString(value)
+			defer C.free(unsafe.Pointer(cValue))
+			result = C.virTypedParamsAddString(&params, &nParams, &maxParams, cName, cValue)
+		case []string:
+			// multi-valued parameters such as the list of disks to migrate
+			// are encoded as repeated entries with the same name
+			for _, v := range value {
+				cValue := C.CString(v)
+				defer C.free(unsafe.Pointer(cValue))
+				if result = C.virTypedParamsAddString(&params, &nParams, &maxParams, cName, cValue); result == -1 {
+					break
+				}
+			}
+		default:
+			C.virTypedParamsFree(params, nParams)
+			return nil, 0, errors.New("Mismatched parameter value type: " + param.Name)
+		}
+		if result == -1 {
+			err = GetLastError()
+			C.virTypedParamsFree(params, nParams)
+			return nil, 0, err
+		}
+	}
+	return
+}
+
+// roundTripTypedParams passes params through a C array and back, returning
+// them as libvirt would see them.
+func roundTripTypedParams(params VirTypedParameters) (VirTypedParameters, error) {
+	cParams, cnParams, err := params.loadToCPtr()
+	if err != nil {
+		return nil, err
+	}
+	defer C.virTypedParamsFree(cParams, cnParams)
+	var decoded VirTypedParameters
+	decoded.loadFromCPtr(cParams, int(cnParams))
+	return decoded, nil
+}
+
+// getTypedParams calls get, a wrapper around one of the libvirt getters
+// filling a caller allocated parameter array, first with no array to learn
+// the number of parameters, then with an array of that size, and decodes
+// the result.
+func getTypedParams(get func(params C.virTypedParameterPtr, nParams *C.int) C.int) (VirTypedParameters, error) {
+	var nParams C.int
+	if result := get(nil, &nParams); result == -1 {
+		return nil, GetLastError()
+	}
+	if nParams == 0 {
+		return VirTypedParameters{}, nil
+	}
+
+	cParams := (C.virTypedParameterPtr)(C.calloc(C.size_t(nParams), C.size_t(unsafe.Sizeof(C.struct__virTypedParameter{}))))
+	defer C.virTypedParamsFree(cParams, nParams)
+	if result := get(cParams, &nParams); result == -1 {
+		return nil, GetLastError()
+	}
+
+	var params VirTypedParameters
+	params.loadFromCPtr(cParams, int(nParams))
+	return params, nil
+}
+
+// typedParamField binds a typed parameter to the struct field holding its
+// value. Numeric and boolean fields come with a flag telling whether the
+// parameter is present, since zero is usually meaningful; string and list
+// fields have none and are present when not empty.
+type typedParamField struct {
+	name  string
+	set   *bool
+	value interface{}
+}
+
+// encodeTypedParams returns the parameters present in fields.
+func encodeTypedParams(fields []typedParamField) VirTypedParameters {
+	params := VirTypedParameters{}
+	for _, field := range fields {
+		if field.set != nil && !*field.set {
+			continue
+		}
+		var value interface{}
+		switch v := field.value.(type) {
+		case *int:
+			value = *v
+		case *uint:
+			value = *v
+		case *int64:
+			value = *v
+		case *uint64:
+			value = *v
+		case *float64:
+			value = *v
+		case *bool:
+			value = *v
+		case *string:
+			if *v == "" {
+				continue
+			}
+			value = *v
+		case *[]string:
+			if len(*v) == 0 {
+				continue
+			}
+			value = *v
+		}
+		params = append(params, VirTypedParameter{Name: field.name, Value: value})
+	}
+	return params
+}
+
+// decodeTypedParams stores the parameters into the matching fields, and
+// marks them present. Parameters of unknown name or unexpected type are
+// ignored; repeated string parameters are appended to list fields.
+func decodeTypedParams(fields []typedParamField, params VirTypedParameters) {
+	byName := make(map[string]typedParamField, len(fields))
+	for _, field := range fields {
+		byName[field.name] = field
+	}
+	for _, param := range params {
+		field, ok := byName[param.Name]
+		if !ok {
+			continue
+		}
+		ok = false
+		switch v := field.value.(type) {
+		case *int:
+			var value int
+			if value, ok = param.Value.(int); ok {
+				*v = value
+			}
+		case *uint:
+			var value uint32
+			if value, ok = param.Value.(uint32); ok {
+				*v = uint(value)
+			}
+		case *int64:
+			var value int64
+			if value, ok = param.Value.(int64); ok {
+				*v = value
+			}
+		case *uint64:
+			var value uint64
+			if value, ok = param.Value.(uint64); ok {
+				*v = value
+			}
+		case *float64:
+			var value float64
+			if value, ok = param.Value.(float64); ok {
+				*v = value
+			}
+		case *bool:
+			var value bool
+			if value, ok = param.Value.(bool); ok {
+				*v = value
+			}
+		case *string:
+			var value string
+			if value, ok = param.Value.(string); ok {
+				*v = value
+			}
+		case *[]string:
+			var value string
+			if value, ok = param.Value.(string); ok {
+				*v = append(*v, value)
+			}
+		}
+		if ok && field.set != nil {
+			*field.set = true
+		}
+	}
+}
+
 func (d *VirDomain) Free() error {
 	if result := C.virDomainFree(d.ptr); result != 0 {
 		return GetLastError()
@@ -271,6 +475,46 @@ func (d *VirDomain) GetInfo() (VirDomainInfo, error) {
 	return di, nil
 }
 
+// GetHostname returns the hostname of the guest, as reported by the guest
+// agent or the hypervisor.
+func (d *VirDomain) GetHostname(flags uint32) (string, error) {
+	result := C.virDomainGetHostname(d.ptr, C.uint(flags))
+	if result == nil {
+		return "", GetLastError()
+	}
+	defer C.free(unsafe.Pointer(result))
+	return C.GoString(result), nil
+}
+
+// GetOSType returns the OS type of the domain, such as "hvm" or "linux".
+func (d *VirDomain) GetOSType() (string, error) {
+	result := C.virDomainGetOSType(d.ptr)
+	if result == nil {
+		return "", GetLastError()
+	}
+	defer C.free(unsafe.Pointer(result))
+	return C.GoString(result), nil
+}
+
+// GetMaxMemory returns the maximum memory of the domain in KiB.
+func (d *VirDomain) GetMaxMemory() (uint64, error) {
+	result := C.virDomainGetMaxMemory(d.ptr)
+	if result == 0 {
+		return 0, GetLastError()
+	}
+	return uint64(result), nil
+}
+
+// GetMaxVcpus returns the maximum number of virtual CPUs of the running
+// domain.
+func (d *VirDomain) GetMaxVcpus() (int, error) {
+	result := C.virDomainGetMaxVcpus(d.ptr)
+	if result == -1 {
+		return 0, GetLastError()
+	}
+	return int(result), nil
+}
+
 func (d *VirDomain) GetXMLDesc(flags uint32) (string, error) {
 	result := C.virDomainGetXMLDesc(d.ptr, C.uint(flags))
 	if result == nil {
@@ -327,32 +571,6 @@ func (d *VirDomain) GetCPUStats(params *VirTypedParameters, nParams int, startCp
 	return result, nil
 }
 
-// Warning: No test written for this function
-func (d *VirDomain) GetInterfaceParameters(device string, params *VirTypedParameters, nParams *int, flags uint32) (int, error) {
-	var cParams C.virTypedParameterPtr
-
-	if params != nil && *nParams > 0 {
-		cParams = (C.virTypedParameterPtr)(C.calloc(C.size_t(*nParams), C.size_t(unsafe.Sizeof(C.struct__virTypedParameter{}))))
-		defer C.virTypedParamsFree(cParams, C.int(*nParams))
-	} else {
-		cParams = nil
-	}
-
-	cDevice := C.CString(device)
-	defer C.free(unsafe.Pointer(cDevice))
-	result := int(C.virDomainGetInterfaceParameters(d.ptr, cDevice,
-		(C.virTypedParameterPtr)(cParams), (*C.int)(unsafe.Pointer(nParams)), C.uint(flags)))
-	if result == -1 {
-		return result, GetLastError()
-	}
-
-	if params != nil && *nParams > 0 {
-		params.loadFromCPtr(cParams, *nParams)
-	}
-
-	return result, nil
-}
-
 func (d *VirDomain) GetMetadata(tipus int, uri string, flags uint32) (string, error) {
 	var cUri *C.char
 	if uri != "" {
@@ -494,6 +712,62 @@ func (d *VirDomain) ShutdownFlags(flags uint) error {
 	return nil
 }
 
+// Reset resets the domain immediately, as the reset button of a physical
+// machine would, without any guest cooperation.
+func (d *VirDomain) Reset(flags uint32) error {
+	result := C.virDomainReset(d.ptr, C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
+
+// InjectNMI sends a non-maskable interrupt to the domain, typically to make
+// a hung guest kernel panic and write a crash dump.
+func (d *VirDomain) InjectNMI(flags uint32) error {
+	result := C.virDomainInjectNMI(d.ptr, C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
+
+// PMSuspendForDuration asks the guest agent to suspend the domain to
+// target. If duration is not zero, the domain wakes up again after that
+// many seconds; otherwise it sleeps until PMWakeup is called.
+func (d *VirDomain) PMSuspendForDuration(target NodeSuspendTarget, duration uint64, flags uint32) error {
+	result := C.virDomainPMSuspendForDuration(d.ptr, C.uint(target), C.ulonglong(duration), C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
+
+// PMWakeup wakes up a domain suspended to memory by PMSuspendForDuration.
+func (d *VirDomain) PMWakeup(flags uint32) error {
+	result := C.virDomainPMWakeup(d.ptr, C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
+
+// FSTrim asks the guest agent to discard unused blocks of the file system
+// mounted at mountpoint, or of all file systems if mountpoint is empty.
+// Free ranges shorter than minimum bytes may be ignored.
+func (d *VirDomain) FSTrim(mountpoint string, minimum uint64, flags uint32) error {
+	var cMountpoint *C.char
+	if mountpoint != "" {
+		cMountpoint = C.CString(mountpoint)
+		defer C.free(unsafe.Pointer(cMountpoint))
+	}
+	result := C.virDomainFSTrim(d.ptr, cMountpoint, C.ulonglong(minimum), C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
+
 func (d *VirDomain) AttachDevice(xml string) error {
 	cXml := C.CString(xml)
 	defer C.free(unsafe.Pointer(cXml))
@@ -822,6 +1096,58 @@ func (d *VirDomain) PinVcpuFlags(vcpu uint, cpuMap []uint32, flags uint, maxCPUs
 	return nil
 }
 
+// GetVcpuPinInfo returns the CPUs each virtual CPU of the domain may run
+// on, indexed by virtual CPU, in the running domain or its persistent
+// configuration depending on the VIR_DOMAIN_AFFECT_* flags.
+func (d *VirDomain) GetVcpuPinInfo(maxCPUs uint32, flags uint32) ([][]uint32, error) {
+	nVcpus := C.virDomainGetVcpusFlags(d.ptr, C.uint(flags)|C.VIR_DOMAIN_VCPU_MAXIMUM)
+	if nVcpus == -1 {
+		return nil, GetLastError()
+	}
+
+	mapLen := virCpuMapLen(maxCPUs)
+	bufSize := int(mapLen) * int(nVcpus)
+	cpuMaps := (*C.uchar)(C.malloc(C.size_t(bufSize)))
+	defer C.free(unsafe.Pointer(cpuMaps))
+
+	result := C.virDomainGetVcpuPinInfo(d.ptr, nVcpus, cpuMaps, mapLen, C.uint(flags))
+	if result == -1 {
+		return nil, GetLastError()
+	}
+
+	bytesCpuMaps := C.GoBytes(unsafe.Pointer(cpuMaps), C.int(bufSize))
+	out := make([][]uint32, int(result))
+	for i := range out {
+		out[i] = extractCpuMask(bytesCpuMaps, i, int(mapLen))
+	}
+	return out, nil
+}
+
+// GetEmulatorPinInfo returns the CPUs the emulator threads of the domain,
+// those not running virtual CPUs or IOThreads, may run on.
+func (d *VirDomain) GetEmulatorPinInfo(maxCPUs uint32, flags uint32) ([]uint32, error) {
+	mapLen := virCpuMapLen(maxCPUs)
+	cpuMap := (*C.uchar)(C.malloc(C.size_t(mapLen)))
+	defer C.free(unsafe.Pointer(cpuMap))
+
+	result := C.virDomainGetEmulatorPinInfo(d.ptr, cpuMap, mapLen, C.uint(flags))
+	if result == -1 {
+		return nil, GetLastError()
+	}
+	return extractCpuMask(C.GoBytes(unsafe.Pointer(cpuMap), mapLen), 0, int(mapLen)), nil
+}
+
+// PinEmulator restricts the emulator threads of the domain to the CPUs in
+// cpuMap.
+func (d *VirDomain) PinEmulator(cpuMap []uint32, flags uint, maxCPUs uint32) error {
+	cpumap, maplen := cpuMask(cpuMap, maxCPUs)
+	result := C.virDomainPinEmulator(d.ptr, cpumap, maplen, C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
+
 func (d *VirDomain) BlockJobAbort(disk string, flags uint32) error {
 
 	cDisk := C.CString(disk)
@@ -857,6 +1183,13 @@ func (b *VirDomainBlockJobInfo) End() uint64 {
 }
 
 func (d *VirDomain) GetBlockJobInfo(disk string, flags uint32) (VirDomainBlockJobInfo, error) {
+	info, _, err := d.getBlockJobInfo(disk, flags)
+	return info, err
+}
+
+// getBlockJobInfo is GetBlockJobInfo also reporting whether a job is
+// running on the disk.
+func (d *VirDomain) getBlockJobInfo(disk string, flags uint32) (VirDomainBlockJobInfo, bool, error) {
 	var (
 		info = VirDomainBlockJobInfo{}
 		ptr  C.virDomainBlockJobInfo
@@ -868,10 +1201,82 @@ func (d *VirDomain) GetBlockJobInfo(disk string, flags uint32) (VirDomainBlockJo
 	result := int(C.virDomainGetBlockJobInfo(d.ptr, cDisk, (*C.virDomainBlockJobInfo)(unsafe.Pointer(&ptr)), C.uint(flags)))
 	if result == -1 {
 
-		return info, GetLastError()
+		return info, false, GetLastError()
 	}
 
 	info.ptr = ptr
 
-	return info, nil
+	return info, result == 1, nil
+}
+
+// BlockCommit merges the images from top down to base in the backing
+// chain of disk into base. Empty base and top select the default images.
+func (d *VirDomain) BlockCommit(disk string, base string, top string, bandwidth uint64, flags BlockCommitFlags) error {
+	cDisk := C.CString(disk)
+	defer C.free(unsafe.Pointer(cDisk))
+	var cBase, cTop *C.char
+	if base != "" {
+		cBase = C.CString(base)
+		defer C.free(unsafe.Pointer(cBase))
+	}
+	if top != "" {
+		cTop = C.CString(top)
+		defer C.free(unsafe.Pointer(cTop))
+	}
+	result := C.virDomainBlockCommit(d.ptr, cDisk, cBase, cTop, C.ulong(bandwidth), C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
+
+// BlockPull populates disk with the data of its whole backing chain.
+func (d *VirDomain) BlockPull(disk string, bandwidth uint64, flags BlockPullFlags) error {
+	cDisk := C.CString(disk)
+	defer C.free(unsafe.Pointer(cDisk))
+	result := C.virDomainBlockPull(d.ptr, cDisk, C.ulong(bandwidth), C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
+
+// BlockRebase pulls the backing chain of disk above base into it, or with
+// VIR_DOMAIN_BLOCK_REBASE_COPY, starts copying disk to base. An empty base
+// pulls the whole chain.
+func (d *VirDomain) BlockRebase(disk string, base string, bandwidth uint64, flags BlockRebaseFlags) error {
+	cDisk := C.CString(disk)
+	defer C.free(unsafe.Pointer(cDisk))
+	var cBase *C.char
+	if base != "" {
+		cBase = C.CString(base)
+		defer C.free(unsafe.Pointer(cBase))
+	}
+	result := C.virDomainBlockRebase(d.ptr, cDisk, cBase, C.ulong(bandwidth), C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
+
+func (d *VirDomain) BlockJobSetSpeed(disk string, bandwidth uint64, flags BlockJobSetSpeedFlags) error {
+	cDisk := C.CString(disk)
+	defer C.free(unsafe.Pointer(cDisk))
+	result := C.virDomainBlockJobSetSpeed(d.ptr, cDisk, C.ulong(bandwidth), C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
+
+// BlockResize changes the size of disk, in KiB unless
+// VIR_DOMAIN_BLOCK_RESIZE_BYTES is given.
+func (d *VirDomain) BlockResize(disk string, size uint64, flags BlockResizeFlags) error {
+	cDisk := C.CString(disk)
+	defer C.free(unsafe.Pointer(cDisk))
+	result := C.virDomainBlockResize(d.ptr, cDisk, C.ulonglong(size), C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
 }
@@ -1,4 +1,4 @@
-// +build libvirt.1.2.14
+// +build libvirt.1.2.14 libvirt.1.2.16 libvirt.1.3.3 libvirt.3.7.0 libvirt.3.9.0 libvirt.4.10.0 libvirt.5.7.0 libvirt.6.10.0
 
 package libvirt
 
@@ -13,7 +13,10 @@ import "C"
 
 import (
 	"errors"
+	"fmt"
+	"os"
 	"reflect"
+	"time"
 	"unsafe"
 )
 
@@ -72,53 +75,30 @@ func (d *VirDomain) ListAllInterfaceAddresses(src uint) ([]VirDomainInterface, e
 	return ifaces, nil
 }
 
-func (dest *VirTypedParameters) loadToCPtr() (params C.virTypedParameterPtr, nParams C.int, err error) {
-
-	var maxParams C.int = 0
-	params = nil
-
-	for _, param := range *dest {
-
+// checkBlockCopyParams rejects the parameters BlockCopy does not know, or
+// given with another type than libvirt expects, before any is sent.
+func checkBlockCopyParams(params VirTypedParameters) error {
+	for _, param := range params {
+		var ok bool
 		switch param.Name {
 		case VIR_DOMAIN_BLOCK_COPY_GRANULARITY:
-			value, ok := param.Value.(uint)
-			if !ok {
-				err = errors.New("Mismatched parameter value type")
-				return
-			}
-
-			cName := C.CString(param.Name)
-			defer C.free(unsafe.Pointer(cName))
-
-			result := C.virTypedParamsAddUInt(&params, &nParams, &maxParams, cName, C.uint(value))
-			if result == -1 {
-				err = GetLastError()
-				return
-			}
+			_, ok = param.Value.(uint)
 		case VIR_DOMAIN_BLOCK_COPY_BANDWIDTH, VIR_DOMAIN_BLOCK_COPY_BUF_SIZE:
-			value, ok := param.Value.(uint64)
-			if !ok {
-				err = errors.New("Mismatched parameter value type")
-				return
-			}
-
-			cName := C.CString(param.Name)
-			defer C.free(unsafe.Pointer(cName))
-
-			result := C.virTypedParamsAddULLong(&params, &nParams, &maxParams, cName, C.ulonglong(value))
-			if result == -1 {
-				err = GetLastError()
-				return
-			}
+			_, ok = param.Value.(uint64)
 		default:
-			err = errors.New("Unknown parameter name: " + param.Name)
-			return
+			return errors.New("Unknown parameter name: " + param.Name)
+		}
+		if !ok {
+			return errors.New("Mismatched parameter value type")
 		}
 	}
-	return
+	return nil
 }
 
 func (d *VirDomain) BlockCopy(disk string, destXML string, params VirTypedParameters, flags uint32) error {
+	if err := checkBlockCopyParams(params); err != nil {
+		return err
+	}
 
 	cDisk := C.CString(disk)
 	defer C.free(unsafe.Pointer(cDisk))
@@ -137,3 +117,214 @@ func (d *VirDomain) BlockCopy(disk string, destXML string, params VirTypedParame
 	}
 	return nil
 }
+
+// OpenGraphicsFD is like OpenGraphics, except that libvirt creates the
+// connection. The caller must close the returned file.
+func (d *VirDomain) OpenGraphicsFD(idx uint32, flags uint32) (*os.File, error) {
+	fd := C.virDomainOpenGraphicsFD(d.ptr, C.uint(idx), C.uint(flags))
+	if fd == -1 {
+		return nil, GetLastError()
+	}
+	return os.NewFile(uintptr(fd), fmt.Sprintf("graphics%d", idx)), nil
+}
+
+// CoreDumpWithFormat is like CoreDump, but with VIR_DUMP_MEMORY_ONLY the
+// dump can be written in a compressed kdump format or as a Windows crash
+// dump instead of ELF.
+func (d *VirDomain) CoreDumpWithFormat(to string, format DomainCoreDumpFormat, flags DomainCoreDumpFlags) error {
+	cTo := C.CString(to)
+	defer C.free(unsafe.Pointer(cTo))
+	result := C.virDomainCoreDumpWithFormat(d.ptr, cTo, C.uint(format), C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
+
+// cStringArray converts strs to a C array of strings, as taken by FSFreeze
+// and FSThaw. The returned function frees the C strings.
+func cStringArray(strs []string) (**C.char, C.uint, func()) {
+	if len(strs) == 0 {
+		return nil, 0, func() {}
+	}
+	cList := make([]*C.char, len(strs))
+	for i, str := range strs {
+		cList[i] = C.CString(str)
+	}
+	free := func() {
+		for _, cStr := range cList {
+			C.free(unsafe.Pointer(cStr))
+		}
+	}
+	return &cList[0], C.uint(len(cList)), free
+}
+
+// goStringArray copies the n strings of the C array cList, without freeing
+// them.
+func goStringArray(cList **C.char, n int) []string {
+	hdr := reflect.SliceHeader{
+		Data: uintptr(unsafe.Pointer(cList)),
+		Len:  n,
+		Cap:  n,
+	}
+	cSlice := *(*[]*C.char)(unsafe.Pointer(&hdr))
+	strs := make([]string, n)
+	for i, cStr := range cSlice {
+		strs[i] = C.GoString(cStr)
+	}
+	return strs
+}
+
+// FSFreeze asks the guest agent to freeze the given file systems of the
+// domain, or all of them if mountpoints is empty, and returns how many were
+// frozen. They must be thawed with FSThaw.
+func (d *VirDomain) FSFreeze(mountpoints []string, flags uint32) (int, error) {
+	cList, nList, free := cStringArray(mountpoints)
+	defer free()
+	result := C.virDomainFSFreeze(d.ptr, cList, nList, C.uint(flags))
+	if result == -1 {
+		return 0, GetLastError()
+	}
+	return int(result), nil
+}
+
+// FSThaw thaws file systems frozen by FSFreeze, or all of them if
+// mountpoints is empty, and returns how many were thawed.
+func (d *VirDomain) FSThaw(mountpoints []string, flags uint32) (int, error) {
+	cList, nList, free := cStringArray(mountpoints)
+	defer free()
+	result := C.virDomainFSThaw(d.ptr, cList, nList, C.uint(flags))
+	if result == -1 {
+		return 0, GetLastError()
+	}
+	return int(result), nil
+}
+
+// FreezeScope freezes the given file systems of the domain, as FSFreeze
+// does, runs fn and thaws them again, whether fn returns an error or
+// panics. This is typically used around CreateSnapshotXML with
+// VIR_DOMAIN_SNAPSHOT_CREATE_DISK_ONLY to get consistent disks. The error
+// of fn takes precedence over that of FSThaw.
+func (d *VirDomain) FreezeScope(mountpoints []string, fn func() error) error {
+	return freezeScope(func() error {
+		_, err := d.FSFreeze(mountpoints, 0)
+		return err
+	}, func() error {
+		_, err := d.FSThaw(mountpoints, 0)
+		return err
+	}, fn)
+}
+
+// freezeScope runs fn between freeze and thaw, see FreezeScope.
+func freezeScope(freeze func() error, thaw func() error, fn func() error) (err error) {
+	if err := freeze(); err != nil {
+		return err
+	}
+	defer func() {
+		if thawErr := thaw(); thawErr != nil && err == nil {
+			err = thawErr
+		}
+	}()
+	return fn()
+}
+
+type DomainFSInfo struct {
+	Mountpoint string   // Path of the mount point in the guest
+	Name       string   // Device name in the guest, e.g. "sda1"
+	FSType     string   // File system type, e.g. "ext4"
+	DevAlias   []string // Aliases of the disks holding the file system
+}
+
+// GetFSInfo asks the guest agent for the mounted file systems of the
+// domain.
+func (d *VirDomain) GetFSInfo(flags uint32) ([]DomainFSInfo, error) {
+	var cList *C.virDomainFSInfoPtr
+	numInfo := int(C.virDomainGetFSInfo(d.ptr, (**C.virDomainFSInfoPtr)(&cList), C.uint(flags)))
+	if numInfo == -1 {
+		return nil, GetLastError()
+	}
+
+	hdr := reflect.SliceHeader{
+		Data: uintptr(unsafe.Pointer(cList)),
+		Len:  numInfo,
+		Cap:  numInfo,
+	}
+	infoSlice := *(*[]C.virDomainFSInfoPtr)(unsafe.Pointer(&hdr))
+
+	infos := make([]DomainFSInfo, numInfo)
+	for i := 0; i < numInfo; i++ {
+		infos[i].Mountpoint = C.GoString(infoSlice[i].mountpoint)
+		infos[i].Name = C.GoString(infoSlice[i].name)
+		infos[i].FSType = C.GoString(infoSlice[i].fstype)
+
+		infos[i].DevAlias = goStringArray(infoSlice[i].devAlias, int(infoSlice[i].ndevAlias))
+		C.virDomainFSInfoFree(infoSlice[i])
+	}
+	C.free(unsafe.Pointer(cList))
+	return infos, nil
+}
+
+// GetTime asks the guest agent for the time of the guest clock.
+func (d *VirDomain) GetTime(flags uint32) (time.Time, error) {
+	var seconds C.longlong
+	var nseconds C.uint
+	result := C.virDomainGetTime(d.ptr, &seconds, &nseconds, C.uint(flags))
+	if result == -1 {
+		return time.Time{}, GetLastError()
+	}
+	return time.Unix(int64(seconds), int64(nseconds)), nil
+}
+
+// SetTime sets the guest clock to t through the guest agent. With
+// VIR_DOMAIN_TIME_SYNC t is ignored and the guest resynchronizes its clock
+// from the hardware clock instead, as needed after a resume.
+func (d *VirDomain) SetTime(t time.Time, flags uint32) error {
+	result := C.virDomainSetTime(d.ptr, C.longlong(t.Unix()), C.uint(t.Nanosecond()), C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
+
+type DomainIOThreadInfo struct {
+	IOThreadID uint
+	CpuMap     []uint32 // CPUs the IOThread may run on
+}
+
+// GetIOThreadInfo returns the IOThreads of the running domain or its
+// persistent configuration, depending on the VIR_DOMAIN_AFFECT_* flags.
+func (d *VirDomain) GetIOThreadInfo(flags uint32) ([]DomainIOThreadInfo, error) {
+	var cList *C.virDomainIOThreadInfoPtr
+	numThreads := int(C.virDomainGetIOThreadInfo(d.ptr, (**C.virDomainIOThreadInfoPtr)(&cList), C.uint(flags)))
+	if numThreads == -1 {
+		return nil, GetLastError()
+	}
+
+	hdr := reflect.SliceHeader{
+		Data: uintptr(unsafe.Pointer(cList)),
+		Len:  numThreads,
+		Cap:  numThreads,
+	}
+	threadSlice := *(*[]C.virDomainIOThreadInfoPtr)(unsafe.Pointer(&hdr))
+
+	threads := make([]DomainIOThreadInfo, numThreads)
+	for i := 0; i < numThreads; i++ {
+		mapLen := threadSlice[i].cpumaplen
+		threads[i].IOThreadID = uint(threadSlice[i].iothread_id)
+		threads[i].CpuMap = extractCpuMask(C.GoBytes(unsafe.Pointer(threadSlice[i].cpumap), mapLen), 0, int(mapLen))
+		C.virDomainIOThreadInfoFree(threadSlice[i])
+	}
+	C.free(unsafe.Pointer(cList))
+	return threads, nil
+}
+
+// PinIOThread restricts the IOThread iothreadID of the domain to the CPUs
+// in cpuMap.
+func (d *VirDomain) PinIOThread(iothreadID uint, cpuMap []uint32, flags uint, maxCPUs uint32) error {
+	cpumap, maplen := cpuMask(cpuMap, maxCPUs)
+	result := C.virDomainPinIOThread(d.ptr, C.uint(iothreadID), cpumap, maplen, C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
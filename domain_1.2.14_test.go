@@ -1,13 +1,19 @@
-// +build libvirt.1.2.14
+// +build libvirt.1.2.14 libvirt.1.2.16 libvirt.1.3.3 libvirt.3.7.0 libvirt.3.9.0 libvirt.4.10.0 libvirt.5.7.0 libvirt.6.10.0
 
 package libvirt
 
 import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestDomainListAllInterfaceAddresses(t *testing.T) {
-	dom, conn := buildTestQEMUDomain()
+	dom, conn := buildTestQEMUDomain("")
 	defer func() {
 		dom.Free()
 		if res, _ := conn.CloseConnection(); res != 0 {
@@ -135,3 +141,200 @@ func TestDomainBlockCopy(t *testing.T) {
 
 	return
 }
+
+func TestDomainOpenGraphicsFD(t *testing.T) {
+	conn := buildTestQEMUConnection()
+	defer func() {
+		if res, _ := conn.CloseConnection(); res != 0 {
+			t.Errorf("CloseConnection() == %d, expected 0", res)
+		}
+	}()
+	dom := buildTestQEMUGraphicsDomain(t, conn)
+	defer func() {
+		dom.Destroy()
+		dom.Free()
+	}()
+
+	client, err := dom.OpenGraphicsFD(0, VIR_DOMAIN_OPEN_GRAPHICS_SKIPAUTH)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	banner := make([]byte, 12)
+	if _, err := io.ReadFull(client, banner); err != nil {
+		t.Fatal(err)
+	}
+	if string(banner[:4]) != "RFB " {
+		t.Errorf("unexpected RFB banner %q", banner)
+	}
+}
+
+func TestDomainCoreDumpWithFormat(t *testing.T) {
+	dom, conn := buildTestDomain()
+	defer func() {
+		dom.Undefine()
+		dom.Free()
+		if res, _ := conn.CloseConnection(); res != 0 {
+			t.Errorf("CloseConnection() == %d, expected 0", res)
+		}
+	}()
+	if err := dom.Create(); err != nil {
+		t.Fatal(err)
+	}
+	defer dom.Destroy()
+
+	file, err := ioutil.TempFile("", "libvirt-go-test-core")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+	defer os.Remove(file.Name())
+	if err := dom.CoreDumpWithFormat(file.Name(), VIR_DOMAIN_CORE_DUMP_FORMAT_RAW, VIR_DUMP_LIVE); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBlockCopyParams(t *testing.T) {
+	// The parameters of TestDomainBlockCopy reach libvirt with their types
+	params := VirTypedParameters{
+		{VIR_DOMAIN_BLOCK_COPY_BANDWIDTH, uint64(2147483648)},
+		{VIR_DOMAIN_BLOCK_COPY_BUF_SIZE, uint64(0)},
+		{VIR_DOMAIN_BLOCK_COPY_GRANULARITY, uint(512)},
+	}
+	if err := checkBlockCopyParams(params); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := roundTripTypedParams(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := VirTypedParameters{
+		{VIR_DOMAIN_BLOCK_COPY_BANDWIDTH, uint64(2147483648)},
+		{VIR_DOMAIN_BLOCK_COPY_BUF_SIZE, uint64(0)},
+		{VIR_DOMAIN_BLOCK_COPY_GRANULARITY, uint32(512)},
+	}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("decoded %#v, expected %#v", decoded, expected)
+	}
+
+	for _, test := range []struct {
+		param VirTypedParameter
+		err   string
+	}{
+		{VirTypedParameter{VIR_DOMAIN_BLOCK_COPY_GRANULARITY, uint64(512)}, "Mismatched parameter value type"},
+		{VirTypedParameter{VIR_DOMAIN_BLOCK_COPY_BANDWIDTH, 100}, "Mismatched parameter value type"},
+		{VirTypedParameter{"speed", uint64(100)}, "Unknown parameter name: speed"},
+	} {
+		err := checkBlockCopyParams(VirTypedParameters{test.param})
+		if err == nil || err.Error() != test.err {
+			t.Errorf("checkBlockCopyParams(%v) == %v, expected %q", test.param, err, test.err)
+		}
+	}
+}
+
+func TestCStringArray(t *testing.T) {
+	strs := []string{"/", "/boot", ""}
+	cList, n, free := cStringArray(strs)
+	defer free()
+	if n != 3 {
+		t.Fatalf("cStringArray() length == %d, expected 3", n)
+	}
+	if got := goStringArray(cList, int(n)); !reflect.DeepEqual(got, strs) {
+		t.Errorf("cStringArray() == %q, expected %q", got, strs)
+	}
+
+	cList, n, free = cStringArray(nil)
+	defer free()
+	if cList != nil || n != 0 {
+		t.Errorf("cStringArray(nil) == %v, %d, expected nil, 0", cList, n)
+	}
+}
+
+func TestFreezeScope(t *testing.T) {
+	var calls []string
+	record := func(name string, err error) func() error {
+		return func() error {
+			calls = append(calls, name)
+			return err
+		}
+	}
+	fnErr := errors.New("snapshot failed")
+	thawErr := errors.New("thaw failed")
+
+	for _, test := range []struct {
+		freeze, thaw, fn error
+		calls            []string
+		err              error
+	}{
+		{nil, nil, nil, []string{"freeze", "fn", "thaw"}, nil},
+		{errors.New("no agent"), nil, nil, []string{"freeze"}, errors.New("no agent")},
+		{nil, nil, fnErr, []string{"freeze", "fn", "thaw"}, fnErr},
+		{nil, thawErr, nil, []string{"freeze", "fn", "thaw"}, thawErr},
+		{nil, thawErr, fnErr, []string{"freeze", "fn", "thaw"}, fnErr},
+	} {
+		calls = nil
+		err := freezeScope(record("freeze", test.freeze), record("thaw", test.thaw), record("fn", test.fn))
+		if !reflect.DeepEqual(calls, test.calls) {
+			t.Errorf("freezeScope() called %v, expected %v", calls, test.calls)
+		}
+		if !reflect.DeepEqual(err, test.err) {
+			t.Errorf("freezeScope() == %v, expected %v", err, test.err)
+		}
+	}
+
+	calls = nil
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("recovered %v, expected the panic of fn", r)
+			}
+		}()
+		freezeScope(record("freeze", nil), record("thaw", nil), func() error {
+			calls = append(calls, "fn")
+			panic("boom")
+		})
+	}()
+	if expected := []string{"freeze", "fn", "thaw"}; !reflect.DeepEqual(calls, expected) {
+		t.Errorf("freezeScope() called %v on panic, expected %v", calls, expected)
+	}
+}
+
+func TestDomainGuestAgentFS(t *testing.T) {
+	conn := buildTestQEMUConnection()
+	defer func() {
+		if res, _ := conn.CloseConnection(); res != 0 {
+			t.Errorf("CloseConnection() == %d, expected 0", res)
+		}
+	}()
+	dom := buildTestQEMURunningDomain(t, conn, "test-guest-agent", "")
+	defer func() {
+		dom.Destroy()
+		dom.Free()
+	}()
+
+	// There is no guest agent, so every call must fail cleanly
+	if _, err := dom.FSFreeze([]string{"/", "/boot"}, 0); err == nil {
+		t.Error("expected error freezing file systems without guest agent")
+	}
+	if _, err := dom.FSThaw(nil, 0); err == nil {
+		t.Error("expected error thawing file systems without guest agent")
+	}
+	if _, err := dom.GetFSInfo(0); err == nil {
+		t.Error("expected error getting file systems without guest agent")
+	}
+	if _, err := dom.GetTime(0); err == nil {
+		t.Error("expected error getting time without guest agent")
+	}
+	if err := dom.SetTime(time.Time{}, VIR_DOMAIN_TIME_SYNC); err == nil {
+		t.Error("expected error setting time without guest agent")
+	}
+
+	called := false
+	err := dom.FreezeScope(nil, func() error {
+		called = true
+		return nil
+	})
+	if err == nil || called {
+		t.Errorf("FreezeScope() == %v, called %v, expected an error before the callback", err, called)
+	}
+}
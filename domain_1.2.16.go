@@ -0,0 +1,50 @@
+// +build libvirt.1.2.16 libvirt.1.3.3 libvirt.3.7.0 libvirt.3.9.0 libvirt.4.10.0 libvirt.5.7.0 libvirt.6.10.0
+
+package libvirt
+
+/*
+#cgo LDFLAGS: -lvirt
+#include <libvirt/libvirt.h>
+#include <libvirt/virterror.h>
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"unsafe"
+)
+
+// SetUserPassword asks the guest agent to change the password of user in
+// the guest. With VIR_DOMAIN_PASSWORD_ENCRYPTED password is a hash as
+// produced by crypt(3) rather than clear text.
+func (d *VirDomain) SetUserPassword(user string, password string, flags DomainSetUserPasswordFlags) error {
+	cUser := C.CString(user)
+	defer C.free(unsafe.Pointer(cUser))
+	cPassword := C.CString(password)
+	defer C.free(unsafe.Pointer(cPassword))
+	result := C.virDomainSetUserPassword(d.ptr, cUser, cPassword, C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
+
+// AddIOThread adds the IOThread iothreadID to the domain, for disks to be
+// assigned to.
+func (d *VirDomain) AddIOThread(iothreadID uint, flags uint32) error {
+	result := C.virDomainAddIOThread(d.ptr, C.uint(iothreadID), C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
+
+// DelIOThread removes the IOThread iothreadID from the domain. It fails
+// while a disk is assigned to the IOThread.
+func (d *VirDomain) DelIOThread(iothreadID uint, flags uint32) error {
+	result := C.virDomainDelIOThread(d.ptr, C.uint(iothreadID), C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+// +build libvirt.1.2.16 libvirt.1.3.3 libvirt.3.7.0 libvirt.3.9.0 libvirt.4.10.0 libvirt.5.7.0 libvirt.6.10.0
+
+package libvirt
+
+import (
+	"testing"
+)
+
+func TestDomainSetUserPassword(t *testing.T) {
+	conn := buildTestQEMUConnection()
+	defer func() {
+		if res, _ := conn.CloseConnection(); res != 0 {
+			t.Errorf("CloseConnection() == %d, expected 0", res)
+		}
+	}()
+	dom := buildTestQEMURunningDomain(t, conn, "test-guest-agent", "")
+	defer func() {
+		dom.Destroy()
+		dom.Free()
+	}()
+
+	// There is no guest agent to change the password
+	if err := dom.SetUserPassword("root", "$6$salt$hash", VIR_DOMAIN_PASSWORD_ENCRYPTED); err == nil {
+		t.Error("expected error setting a password without guest agent")
+	}
+}
+
+func TestDomainIOThreads(t *testing.T) {
+	conn := buildTestQEMUConnection()
+	defer func() {
+		if res, _ := conn.CloseConnection(); res != 0 {
+			t.Errorf("CloseConnection() == %d, expected 0", res)
+		}
+	}()
+	dom, err := conn.DomainDefineXML(`<domain type="qemu">
+		<name>test-iothreads</name>
+		<memory unit="KiB">8192</memory>
+		<iothreads>1</iothreads>
+		<os>
+			<type>hvm</type>
+		</os>
+	</domain>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		dom.Undefine()
+		dom.Free()
+	}()
+	ni, err := conn.GetNodeInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := dom.AddIOThread(2, VIR_DOMAIN_AFFECT_CONFIG); err != nil {
+		t.Fatal(err)
+	}
+	if err := dom.PinIOThread(2, []uint32{0}, VIR_DOMAIN_AFFECT_CONFIG, ni.GetMaxCPUs()); err != nil {
+		t.Fatal(err)
+	}
+	threads, err := dom.GetIOThreadInfo(VIR_DOMAIN_AFFECT_CONFIG)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(threads) != 2 {
+		t.Fatalf("GetIOThreadInfo() returned %d IOThreads, expected 2", len(threads))
+	}
+	if threads[1].IOThreadID != 2 || len(threads[1].CpuMap) != 1 || threads[1].CpuMap[0] != 0 {
+		t.Errorf("GetIOThreadInfo()[1] == %+v, expected IOThread 2 pinned to CPU 0", threads[1])
+	}
+
+	if err := dom.DelIOThread(2, VIR_DOMAIN_AFFECT_CONFIG); err != nil {
+		t.Fatal(err)
+	}
+	if threads, err := dom.GetIOThreadInfo(VIR_DOMAIN_AFFECT_CONFIG); err != nil || len(threads) != 1 {
+		t.Errorf("GetIOThreadInfo() == %+v, %v, expected 1 IOThread", threads, err)
+	}
+}
@@ -0,0 +1,21 @@
+// +build libvirt.1.3.3 libvirt.3.7.0 libvirt.3.9.0 libvirt.4.10.0 libvirt.5.7.0 libvirt.6.10.0
+
+package libvirt
+
+/*
+#cgo LDFLAGS: -lvirt
+#include <libvirt/libvirt.h>
+#include <libvirt/virterror.h>
+#include <stdlib.h>
+*/
+import "C"
+
+// MigrateStartPostCopy switches a migration started with
+// VIR_MIGRATE_POSTCOPY to post-copy mode.
+func (d *VirDomain) MigrateStartPostCopy(flags uint32) error {
+	result := C.virDomainMigrateStartPostCopy(d.ptr, C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
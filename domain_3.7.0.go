@@ -0,0 +1,38 @@
+// +build libvirt.3.7.0 libvirt.3.9.0 libvirt.4.10.0 libvirt.5.7.0 libvirt.6.10.0
+
+package libvirt
+
+/*
+#cgo LDFLAGS: -lvirt
+#include <libvirt/libvirt.h>
+#include <libvirt/virterror.h>
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"unsafe"
+)
+
+// ManagedSaveGetXMLDesc returns the domain XML stored in the managed save
+// image of the domain.
+func (d *VirDomain) ManagedSaveGetXMLDesc(flags uint32) (string, error) {
+	result := C.virDomainManagedSaveGetXMLDesc(d.ptr, C.uint(flags))
+	if result == nil {
+		return "", GetLastError()
+	}
+	defer C.free(unsafe.Pointer(result))
+	return C.GoString(result), nil
+}
+
+// ManagedSaveDefineXML replaces the domain XML stored in the managed save
+// image of the domain, as SaveImageDefineXML does for explicit files.
+func (d *VirDomain) ManagedSaveDefineXML(xml string, flags uint32) error {
+	cXml := C.CString(xml)
+	defer C.free(unsafe.Pointer(cXml))
+	result := C.virDomainManagedSaveDefineXML(d.ptr, cXml, C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+// +build libvirt.3.7.0 libvirt.3.9.0 libvirt.4.10.0 libvirt.5.7.0 libvirt.6.10.0
+
+package libvirt
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+const testSaveDescription = "<description>edited while saved</description>"
+
+// editSaveXML adds a description to the domain XML of a save image, a
+// change that keeps the guest ABI.
+func editSaveXML(t *testing.T, xml string) string {
+	if strings.Contains(xml, testSaveDescription) {
+		t.Fatal("save image XML already edited")
+	}
+	return strings.Replace(xml, "</name>", "</name>"+testSaveDescription, 1)
+}
+
+func TestDomainManagedSaveXML(t *testing.T) {
+	dom, conn := buildTestQEMUDomain("")
+	defer func() {
+		dom.Destroy()
+		dom.ManagedSaveRemove(0)
+		dom.Undefine()
+		dom.Free()
+		if res, _ := conn.CloseConnection(); res != 0 {
+			t.Errorf("CloseConnection() == %d, expected 0", res)
+		}
+	}()
+	if err := dom.Create(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := dom.ManagedSave(0); err != nil {
+		t.Fatal(err)
+	}
+	xml, err := dom.ManagedSaveGetXMLDesc(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dom.ManagedSaveDefineXML(editSaveXML(t, xml), 0); err != nil {
+		t.Fatal(err)
+	}
+	xml, err = dom.ManagedSaveGetXMLDesc(VIR_DOMAIN_SAVE_IMAGE_XML_SECURE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(xml, testSaveDescription) {
+		t.Errorf("edit missing from managed save XML:\n%s", xml)
+	}
+}
+
+func TestDomainSaveImageXML(t *testing.T) {
+	dom, conn := buildTestQEMUDomain("")
+	defer func() {
+		dom.Destroy()
+		dom.Undefine()
+		dom.Free()
+		if res, _ := conn.CloseConnection(); res != 0 {
+			t.Errorf("CloseConnection() == %d, expected 0", res)
+		}
+	}()
+	if err := dom.Create(); err != nil {
+		t.Fatal(err)
+	}
+	file, err := ioutil.TempFile("/var/lib/libvirt/images", "test-save")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+	defer os.Remove(file.Name())
+
+	if err := dom.Save(file.Name()); err != nil {
+		t.Fatal(err)
+	}
+	xml, err := conn.SaveImageGetXMLDesc(file.Name(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.SaveImageDefineXML(file.Name(), editSaveXML(t, xml), 0); err != nil {
+		t.Fatal(err)
+	}
+	xml, err = conn.SaveImageGetXMLDesc(file.Name(), VIR_DOMAIN_SAVE_IMAGE_XML_SECURE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(xml, testSaveDescription) {
+		t.Errorf("edit missing from save image XML:\n%s", xml)
+	}
+
+	if err := conn.Restore(file.Name()); err != nil {
+		t.Fatal(err)
+	}
+	xml, err = dom.GetXMLDesc(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(xml, testSaveDescription) {
+		t.Errorf("edit missing from restored domain XML:\n%s", xml)
+	}
+}
@@ -0,0 +1,23 @@
+// +build libvirt.3.9.0 libvirt.4.10.0 libvirt.5.7.0 libvirt.6.10.0
+
+package libvirt
+
+/*
+#cgo LDFLAGS: -lvirt
+#include <libvirt/libvirt.h>
+#include <libvirt/virterror.h>
+#include <stdlib.h>
+*/
+import "C"
+
+// SetLifecycleAction changes the action taken when the domain goes
+// through the lifecycle event typ, as the on_poweroff, on_reboot and
+// on_crash elements of its XML do. Not every action is allowed for every
+// event; only crashes can dump core, for instance.
+func (d *VirDomain) SetLifecycleAction(typ DomainLifecycle, action DomainLifecycleAction, flags uint32) error {
+	result := C.virDomainSetLifecycleAction(d.ptr, C.uint(typ), C.uint(action), C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
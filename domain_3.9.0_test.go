@@ -0,0 +1,39 @@
+// +build libvirt.3.9.0 libvirt.4.10.0 libvirt.5.7.0 libvirt.6.10.0
+
+package libvirt
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDomainSetLifecycleAction(t *testing.T) {
+	dom, conn := buildTestQEMUDomain("")
+	defer func() {
+		dom.Undefine()
+		dom.Free()
+		if res, _ := conn.CloseConnection(); res != 0 {
+			t.Errorf("CloseConnection() == %d, expected 0", res)
+		}
+	}()
+
+	if err := dom.SetLifecycleAction(VIR_DOMAIN_LIFECYCLE_CRASH, VIR_DOMAIN_LIFECYCLE_ACTION_COREDUMP_DESTROY, VIR_DOMAIN_AFFECT_CONFIG); err != nil {
+		t.Fatal(err)
+	}
+	if err := dom.SetLifecycleAction(VIR_DOMAIN_LIFECYCLE_REBOOT, VIR_DOMAIN_LIFECYCLE_ACTION_DESTROY, VIR_DOMAIN_AFFECT_CONFIG); err != nil {
+		t.Fatal(err)
+	}
+	xml, err := dom.GetXMLDesc(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, element := range []string{"<on_crash>coredump-destroy</on_crash>", "<on_reboot>destroy</on_reboot>"} {
+		if !strings.Contains(xml, element) {
+			t.Errorf("%s missing from domain XML", element)
+		}
+	}
+
+	if err := dom.SetLifecycleAction(VIR_DOMAIN_LIFECYCLE_POWEROFF, VIR_DOMAIN_LIFECYCLE_ACTION_COREDUMP_DESTROY, VIR_DOMAIN_AFFECT_CONFIG); err == nil {
+		t.Error("expected error dumping core on poweroff")
+	}
+}
@@ -0,0 +1,47 @@
+// +build libvirt.4.10.0 libvirt.5.7.0 libvirt.6.10.0
+
+package libvirt
+
+/*
+#cgo LDFLAGS: -lvirt
+#include <libvirt/libvirt.h>
+#include <libvirt/virterror.h>
+#include <stdlib.h>
+*/
+import "C"
+
+// DomainIOThreadParameters tunes the polling of an IOThread, which spins
+// for new requests for a while before sleeping.
+type DomainIOThreadParameters struct {
+	PollMaxNsSet  bool
+	PollMaxNs     uint64 // Longest polling time in nanoseconds, 0 disables polling
+	PollGrowSet   bool
+	PollGrow      uint // Factor to grow the polling time by, 0 to let the hypervisor choose
+	PollShrinkSet bool
+	PollShrink    uint // Divisor to shrink the polling time by, 0 to let the hypervisor choose
+}
+
+func (p *DomainIOThreadParameters) fields() []typedParamField {
+	return []typedParamField{
+		{VIR_DOMAIN_IOTHREAD_POLL_MAX_NS, &p.PollMaxNsSet, &p.PollMaxNs},
+		{VIR_DOMAIN_IOTHREAD_POLL_GROW, &p.PollGrowSet, &p.PollGrow},
+		{VIR_DOMAIN_IOTHREAD_POLL_SHRINK, &p.PollShrinkSet, &p.PollShrink},
+	}
+}
+
+// SetIOThreadParams changes the polling parameters of the IOThread
+// iothreadID of the running domain.
+func (d *VirDomain) SetIOThreadParams(iothreadID uint, params *DomainIOThreadParameters, flags uint32) error {
+	typedParams := encodeTypedParams(params.fields())
+	cParams, cnParams, err := typedParams.loadToCPtr()
+	if err != nil {
+		return err
+	}
+	defer C.virTypedParamsFree(cParams, cnParams)
+
+	result := C.virDomainSetIOThreadParams(d.ptr, C.uint(iothreadID), cParams, cnParams, C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
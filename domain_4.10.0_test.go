@@ -0,0 +1,58 @@
+// +build libvirt.4.10.0 libvirt.5.7.0 libvirt.6.10.0
+
+package libvirt
+
+import (
+	"testing"
+)
+
+func TestIOThreadParametersEncoding(t *testing.T) {
+	params := &DomainIOThreadParameters{
+		PollMaxNsSet: true,
+		PollMaxNs:    32768,
+		PollGrowSet:  true,
+		PollGrow:     2,
+	}
+	encoded := encodeTypedParams(params.fields())
+	if len(encoded) != 2 {
+		t.Fatalf("encoded %v, expected 2 parameters", encoded)
+	}
+	if encoded[0].Name != VIR_DOMAIN_IOTHREAD_POLL_MAX_NS || encoded[0].Value != uint64(32768) {
+		t.Errorf("encoded %v, expected poll_max_ns first", encoded[0])
+	}
+	if encoded[1].Name != VIR_DOMAIN_IOTHREAD_POLL_GROW || encoded[1].Value != uint(2) {
+		t.Errorf("encoded %v, expected poll_grow second", encoded[1])
+	}
+}
+
+func TestDomainSetIOThreadParams(t *testing.T) {
+	conn := buildTestQEMUConnection()
+	defer func() {
+		if res, _ := conn.CloseConnection(); res != 0 {
+			t.Errorf("CloseConnection() == %d, expected 0", res)
+		}
+	}()
+	dom, err := conn.DomainCreateXML(`<domain type="qemu">
+		<name>test-iothread-params</name>
+		<memory unit="KiB">8192</memory>
+		<iothreads>1</iothreads>
+		<os>
+			<type>hvm</type>
+		</os>
+	</domain>`, VIR_DOMAIN_NONE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		dom.Destroy()
+		dom.Free()
+	}()
+
+	params := &DomainIOThreadParameters{PollMaxNsSet: true, PollMaxNs: 32768}
+	if err := dom.SetIOThreadParams(1, params, VIR_DOMAIN_AFFECT_LIVE); err != nil {
+		t.Fatal(err)
+	}
+	if err := dom.SetIOThreadParams(2, params, VIR_DOMAIN_AFFECT_LIVE); err == nil {
+		t.Error("expected error tuning a missing IOThread")
+	}
+}
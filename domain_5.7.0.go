@@ -0,0 +1,32 @@
+// +build libvirt.5.7.0 libvirt.6.10.0
+
+package libvirt
+
+/*
+#cgo LDFLAGS: -lvirt
+#include <libvirt/libvirt.h>
+#include <libvirt/virterror.h>
+#include <stdlib.h>
+*/
+import "C"
+
+// GetGuestInfo asks the guest agent for the information selected by types,
+// a combination of VIR_DOMAIN_GUEST_INFO_* values, or for everything it
+// supports if types is zero.
+func (d *VirDomain) GetGuestInfo(types DomainGuestInfoTypes, flags uint32) (*GuestInfo, error) {
+	var (
+		cParams  C.virTypedParameterPtr
+		cnParams C.int
+	)
+	result := C.virDomainGetGuestInfo(d.ptr, C.uint(types), &cParams, &cnParams, C.uint(flags))
+	if result == -1 {
+		return nil, GetLastError()
+	}
+	defer C.virTypedParamsFree(cParams, cnParams)
+
+	var params VirTypedParameters
+	params.loadFromCPtr(cParams, int(cnParams))
+	info := &GuestInfo{}
+	info.loadFromParams(params)
+	return info, nil
+}
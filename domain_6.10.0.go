@@ -0,0 +1,127 @@
+// +build libvirt.6.10.0
+
+package libvirt
+
+/*
+#cgo LDFLAGS: -lvirt
+#include <libvirt/libvirt.h>
+#include <libvirt/virterror.h>
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"reflect"
+	"strings"
+	"unsafe"
+)
+
+// AuthorizedSSHKeysGet asks the guest agent for the authorized SSH keys of
+// user in the guest, one per entry.
+func (d *VirDomain) AuthorizedSSHKeysGet(user string, flags uint32) ([]string, error) {
+	cUser := C.CString(user)
+	defer C.free(unsafe.Pointer(cUser))
+	var cKeys **C.char
+	numKeys := int(C.virDomainAuthorizedSSHKeysGet(d.ptr, cUser, &cKeys, C.uint(flags)))
+	if numKeys == -1 {
+		return nil, GetLastError()
+	}
+
+	hdr := reflect.SliceHeader{
+		Data: uintptr(unsafe.Pointer(cKeys)),
+		Len:  numKeys,
+		Cap:  numKeys,
+	}
+	keySlice := *(*[]*C.char)(unsafe.Pointer(&hdr))
+
+	keys := make([]string, numKeys)
+	for i := 0; i < numKeys; i++ {
+		keys[i] = C.GoString(keySlice[i])
+		C.free(unsafe.Pointer(keySlice[i]))
+	}
+	C.free(unsafe.Pointer(cKeys))
+	return keys, nil
+}
+
+// AuthorizedSSHKeysSet asks the guest agent to replace the authorized SSH
+// keys of user in the guest with keys. With
+// VIR_DOMAIN_AUTHORIZED_SSH_KEYS_SET_APPEND the keys are added to the
+// existing ones instead, and with VIR_DOMAIN_AUTHORIZED_SSH_KEYS_SET_REMOVE
+// they are removed from them.
+func (d *VirDomain) AuthorizedSSHKeysSet(user string, keys []string, flags DomainAuthorizedSSHKeysSetFlags) error {
+	cUser := C.CString(user)
+	defer C.free(unsafe.Pointer(cUser))
+	cKeys, nKeys, free := cStringArray(keys)
+	defer free()
+	result := C.virDomainAuthorizedSSHKeysSet(d.ptr, cUser, cKeys, nKeys, C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
+
+// diffAuthorizedSSHKeys returns the keys of desired missing from current,
+// and those of current not in desired. Keys are compared without
+// surrounding whitespace; blank lines and comments in current are ignored.
+func diffAuthorizedSSHKeys(current []string, desired []string) (add []string, remove []string) {
+	have := make(map[string]bool, len(current))
+	for _, key := range current {
+		have[strings.TrimSpace(key)] = true
+	}
+	want := make(map[string]bool, len(desired))
+	for _, key := range desired {
+		key = strings.TrimSpace(key)
+		if key == "" || want[key] {
+			continue
+		}
+		want[key] = true
+		if !have[key] {
+			add = append(add, key)
+		}
+	}
+	removed := make(map[string]bool)
+	for _, key := range current {
+		key = strings.TrimSpace(key)
+		if key == "" || strings.HasPrefix(key, "#") || want[key] || removed[key] {
+			continue
+		}
+		removed[key] = true
+		remove = append(remove, key)
+	}
+	return add, remove
+}
+
+// ReconcileAuthorizedSSHKeys makes the authorized SSH keys of user in the
+// guest exactly desired, appending the missing keys before removing the
+// other ones, so that access is never lost halfway, and returns the keys it
+// added and removed. Keys already in place are left untouched, so the file
+// is not rewritten when nothing changed. On error, the keys changed so far
+// are returned along with it.
+func (d *VirDomain) ReconcileAuthorizedSSHKeys(user string, desired []string) (added []string, removed []string, err error) {
+	return reconcileAuthorizedSSHKeys(func() ([]string, error) {
+		return d.AuthorizedSSHKeysGet(user, 0)
+	}, func(keys []string, flags DomainAuthorizedSSHKeysSetFlags) error {
+		return d.AuthorizedSSHKeysSet(user, keys, flags)
+	}, desired)
+}
+
+// reconcileAuthorizedSSHKeys implements ReconcileAuthorizedSSHKeys over the
+// get and set calls of the guest agent.
+func reconcileAuthorizedSSHKeys(get func() ([]string, error), set func([]string, DomainAuthorizedSSHKeysSetFlags) error, desired []string) (added []string, removed []string, err error) {
+	current, err := get()
+	if err != nil {
+		return nil, nil, err
+	}
+	add, remove := diffAuthorizedSSHKeys(current, desired)
+	if len(add) > 0 {
+		if err := set(add, VIR_DOMAIN_AUTHORIZED_SSH_KEYS_SET_APPEND); err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(remove) > 0 {
+		if err := set(remove, VIR_DOMAIN_AUTHORIZED_SSH_KEYS_SET_REMOVE); err != nil {
+			return add, nil, err
+		}
+	}
+	return add, remove, nil
+}
@@ -0,0 +1,93 @@
+// +build libvirt.6.10.0
+
+package libvirt
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffAuthorizedSSHKeys(t *testing.T) {
+	current := []string{
+		"# managed keys",
+		"ssh-ed25519 AAAA1 alice",
+		"ssh-rsa AAAA2 bob",
+		"",
+		"ssh-rsa AAAA2 bob",
+		"ssh-ed25519 AAAA3 carol",
+	}
+	desired := []string{
+		"ssh-ed25519 AAAA1 alice ",
+		"ssh-ed25519 AAAA4 dave",
+		"ssh-ed25519 AAAA4 dave",
+		"ssh-ed25519 AAAA3 carol",
+	}
+	add, remove := diffAuthorizedSSHKeys(current, desired)
+	if expected := []string{"ssh-ed25519 AAAA4 dave"}; !reflect.DeepEqual(add, expected) {
+		t.Errorf("add == %q, expected %q", add, expected)
+	}
+	if expected := []string{"ssh-rsa AAAA2 bob"}; !reflect.DeepEqual(remove, expected) {
+		t.Errorf("remove == %q, expected %q", remove, expected)
+	}
+
+	add, remove = diffAuthorizedSSHKeys(desired, desired)
+	if add != nil || remove != nil {
+		t.Errorf("diff of identical lists == %q, %q", add, remove)
+	}
+}
+
+func TestReconcileAuthorizedSSHKeys(t *testing.T) {
+	var calls []string
+	get := func() ([]string, error) {
+		return []string{"ssh-ed25519 AAAA1 alice", "ssh-rsa AAAA2 bob"}, nil
+	}
+	set := func(keys []string, flags DomainAuthorizedSSHKeysSetFlags) error {
+		switch flags {
+		case VIR_DOMAIN_AUTHORIZED_SSH_KEYS_SET_APPEND:
+			calls = append(calls, "append "+strings.Join(keys, ","))
+		case VIR_DOMAIN_AUTHORIZED_SSH_KEYS_SET_REMOVE:
+			calls = append(calls, "remove "+strings.Join(keys, ","))
+			return errors.New("agent went away")
+		default:
+			t.Errorf("unexpected flags %d", flags)
+		}
+		return nil
+	}
+	desired := []string{"ssh-ed25519 AAAA1 alice", "ssh-ed25519 AAAA4 dave"}
+
+	added, removed, err := reconcileAuthorizedSSHKeys(get, set, desired)
+	expected := []string{"append ssh-ed25519 AAAA4 dave", "remove ssh-rsa AAAA2 bob"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("reconcileAuthorizedSSHKeys() called %q, expected %q", calls, expected)
+	}
+	if err == nil {
+		t.Error("expected the error of the removal")
+	}
+	if !reflect.DeepEqual(added, []string{"ssh-ed25519 AAAA4 dave"}) || removed != nil {
+		t.Errorf("reconcileAuthorizedSSHKeys() == %q, %q, expected the appended key only", added, removed)
+	}
+}
+
+func TestDomainAuthorizedSSHKeys(t *testing.T) {
+	conn := buildTestQEMUConnection()
+	defer func() {
+		if res, _ := conn.CloseConnection(); res != 0 {
+			t.Errorf("CloseConnection() == %d, expected 0", res)
+		}
+	}()
+	dom := buildTestQEMURunningDomain(t, conn, "test-guest-agent", "")
+	defer func() {
+		dom.Destroy()
+		dom.Free()
+	}()
+
+	// There is no guest agent to manage the keys
+	if _, err := dom.AuthorizedSSHKeysGet("root", 0); err == nil {
+		t.Error("expected error getting keys without guest agent")
+	}
+	if _, _, err := dom.ReconcileAuthorizedSSHKeys("root", []string{"ssh-ed25519 AAAA1 alice"}); err == nil {
+		t.Error("expected error reconciling keys without guest agent")
+	}
+}
@@ -0,0 +1,156 @@
+package libvirt
+
+/*
+#cgo LDFLAGS: -lvirt
+#include <libvirt/libvirt.h>
+#include <libvirt/virterror.h>
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"context"
+	"encoding/xml"
+	"time"
+)
+
+// blockJobPollInterval is how often WaitBlockJob checks the job when no
+// event arrives.
+const blockJobPollInterval = 500 * time.Millisecond
+
+// BlockJobStatusUnknown is returned by WaitBlockJob when a polled job went
+// away without telling how it ended. It is not a libvirt status.
+const BlockJobStatusUnknown = -2
+
+// WaitBlockJob waits for the block job running on disk, given by target
+// device, and returns its final status: VIR_DOMAIN_BLOCK_JOB_COMPLETED,
+// VIR_DOMAIN_BLOCK_JOB_FAILED or VIR_DOMAIN_BLOCK_JOB_CANCELED.
+//
+// Copy and active commit jobs do not end on their own. When their mirror
+// is ready, WaitBlockJob either returns VIR_DOMAIN_BLOCK_JOB_READY or, if
+// pivot is true, pivots to the new image with
+// VIR_DOMAIN_BLOCK_JOB_ABORT_PIVOT and waits for the job to complete.
+//
+// Block job events are used when the connection supports them and the
+// application runs an event loop; otherwise the job is polled. A polled
+// job that went away is reported as completed only after a successful
+// pivot, or for a pull that left the disk without backing chain, and as
+// BlockJobStatusUnknown otherwise. The job keeps running if ctx is done first.
+func (d *VirDomain) WaitBlockJob(ctx context.Context, disk string, pivot bool) (int, error) {
+	events := make(chan int, 8)
+	callback := DomainEventCallback(func(c *VirConnection, dom *VirDomain, event interface{}, f func()) int {
+		if e, ok := event.(DomainBlockJobEvent); ok && e.Disk == disk {
+			select {
+			case events <- e.Status:
+			default:
+			}
+		}
+		return 0
+	})
+	conn := VirConnection{ptr: C.virDomainGetConnect(d.ptr)}
+	if callbackId := conn.DomainEventRegister(*d, VIR_DOMAIN_EVENT_ID_BLOCK_JOB_2, &callback, nil); callbackId != -1 {
+		defer conn.DomainEventDeregister(callbackId)
+	}
+
+	ticker := time.NewTicker(blockJobPollInterval)
+	defer ticker.Stop()
+
+	status := -1
+	jobType := VIR_DOMAIN_BLOCK_JOB_TYPE_UNKNOWN
+	pivoted := false
+	for {
+		switch status {
+		case VIR_DOMAIN_BLOCK_JOB_COMPLETED, VIR_DOMAIN_BLOCK_JOB_FAILED, VIR_DOMAIN_BLOCK_JOB_CANCELED:
+			return status, nil
+		case VIR_DOMAIN_BLOCK_JOB_READY:
+			if !pivot {
+				return status, nil
+			}
+			if err := d.BlockJobAbort(disk, VIR_DOMAIN_BLOCK_JOB_ABORT_PIVOT); err != nil {
+				return -1, err
+			}
+			pivoted = true
+		default:
+			info, exists, err := d.getBlockJobInfo(disk, 0)
+			if err != nil {
+				return -1, err
+			}
+			if !exists {
+				return d.endedBlockJobStatus(disk, jobType, pivoted)
+			}
+			jobType = info.Type()
+			// Without events, a mirror is taken as ready once it has
+			// caught up. If it was not ready after all, the pivot fails
+			// with VIR_ERR_BLOCK_COPY_ACTIVE and is retried on the next
+			// poll; any other failure is returned.
+			mirror := jobType == VIR_DOMAIN_BLOCK_JOB_TYPE_COPY || jobType == VIR_DOMAIN_BLOCK_JOB_TYPE_ACTIVE_COMMIT
+			if mirror && info.End() > 0 && info.Cur() == info.End() {
+				if !pivot {
+					return VIR_DOMAIN_BLOCK_JOB_READY, nil
+				}
+				if err := d.BlockJobAbort(disk, VIR_DOMAIN_BLOCK_JOB_ABORT_PIVOT); err == nil {
+					pivoted = true
+				} else if virErr, ok := err.(VirError); !ok || virErr.Code != VIR_ERR_BLOCK_COPY_ACTIVE {
+					return -1, err
+				}
+			}
+		}
+
+		status = -1
+		select {
+		case status = <-events:
+		case <-ticker.C:
+		case <-ctx.Done():
+			return -1, ctx.Err()
+		}
+	}
+}
+
+// endedBlockJobStatus tells how a polled block job of type jobType on
+// disk ended, from what is left of it.
+func (d *VirDomain) endedBlockJobStatus(disk string, jobType int, pivoted bool) (int, error) {
+	if pivoted {
+		// Pivoting waits for the job to complete
+		return VIR_DOMAIN_BLOCK_JOB_COMPLETED, nil
+	}
+	if jobType != VIR_DOMAIN_BLOCK_JOB_TYPE_PULL {
+		return BlockJobStatusUnknown, nil
+	}
+	backed, err := d.diskHasBackingStore(disk)
+	if err != nil {
+		return -1, err
+	}
+	if backed {
+		// Failed, cancelled, or a rebase onto a base image
+		return BlockJobStatusUnknown, nil
+	}
+	return VIR_DOMAIN_BLOCK_JOB_COMPLETED, nil
+}
+
+// diskHasBackingStore tells whether disk, given by target device, has a
+// backing image in the live domain XML.
+func (d *VirDomain) diskHasBackingStore(disk string) (bool, error) {
+	desc, err := d.GetXMLDesc(0)
+	if err != nil {
+		return false, err
+	}
+	var domain struct {
+		Disks []struct {
+			Target struct {
+				Dev string `xml:"dev,attr"`
+			} `xml:"target"`
+			BackingStore *struct {
+				Source *struct{} `xml:"source"`
+			} `xml:"backingStore"`
+		} `xml:"devices>disk"`
+	}
+	if err := xml.Unmarshal([]byte(desc), &domain); err != nil {
+		return false, err
+	}
+	for _, dev := range domain.Disks {
+		if dev.Target.Dev == disk {
+			return dev.BackingStore != nil && dev.BackingStore.Source != nil, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,90 @@
+package libvirt
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+// buildTestBackedImage creates a qcow2 overlay on top of a raw image
+// holding some data, next to the shared test image, and returns the path of
+// the overlay and a function removing both.
+func buildTestBackedImage(t *testing.T) (string, func()) {
+	base, err := ioutil.TempFile("/var/lib/libvirt/images", "test-base")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = base.Write(bytes.Repeat([]byte{0x5a}, 2<<20))
+	base.Close()
+	if err != nil {
+		os.Remove(base.Name())
+		t.Fatal(err)
+	}
+	overlay := base.Name() + ".qcow2"
+	cleanup := func() {
+		os.Remove(overlay)
+		os.Remove(base.Name())
+	}
+	out, err := exec.Command("qemu-img", "create", "-f", "qcow2",
+		"-o", "backing_file="+base.Name()+",backing_fmt=raw", overlay).CombinedOutput()
+	if err != nil {
+		cleanup()
+		t.Fatalf("qemu-img: %s: %s", err, out)
+	}
+	return overlay, cleanup
+}
+
+func TestDomainWaitBlockJob(t *testing.T) {
+	conn := buildTestQEMUConnection()
+	defer func() {
+		if res, _ := conn.CloseConnection(); res != 0 {
+			t.Errorf("CloseConnection() == %d, expected 0", res)
+		}
+	}()
+	image, cleanup := buildTestBackedImage(t)
+	defer cleanup()
+
+	dom, err := conn.DomainCreateXML(`<domain type="qemu">
+		<name>test-wait-block-job</name>
+		<memory unit="KiB">8192</memory>
+		<os>
+			<type>hvm</type>
+		</os>
+		<devices>
+			<disk type='file' device='disk'>
+				<driver name='qemu' type='qcow2'/>
+				<source file='`+image+`'/>
+				<target dev='hda'/>
+			</disk>
+		</devices>
+	</domain>`, VIR_DOMAIN_NONE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		dom.Destroy()
+		dom.Free()
+	}()
+
+	if err := dom.BlockResize("hda", 4<<20, VIR_DOMAIN_BLOCK_RESIZE_BYTES); err != nil {
+		t.Fatal(err)
+	}
+	// Pull the 2MiB of the base image at 1MiB/s, so that the job is seen
+	// running before it goes away
+	if err := dom.BlockPull("hda", 1, 0); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	status, err := dom.WaitBlockJob(ctx, "hda", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != VIR_DOMAIN_BLOCK_JOB_COMPLETED {
+		t.Errorf("WaitBlockJob() == %d, expected %d", status, VIR_DOMAIN_BLOCK_JOB_COMPLETED)
+	}
+}
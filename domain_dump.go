@@ -0,0 +1,93 @@
+package libvirt
+
+/*
+#cgo LDFLAGS: -lvirt
+#include <libvirt/libvirt.h>
+#include <libvirt/virterror.h>
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+	"unsafe"
+)
+
+// dumpProgressInterval is how often CoreDumpToWriter reports progress.
+const dumpProgressInterval = time.Second
+
+// CoreDump dumps the memory of the domain to the file to, for analysis
+// with crash or gdb. The path is on the hypervisor host. Unless
+// VIR_DUMP_LIVE is given, the domain is paused during the dump.
+func (d *VirDomain) CoreDump(to string, flags DomainCoreDumpFlags) error {
+	cTo := C.CString(to)
+	defer C.free(unsafe.Pointer(cTo))
+	result := C.virDomainCoreDump(d.ptr, cTo, C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
+
+// CoreDumpToWriter dumps the memory of the domain into w. The dump goes
+// through a temporary file, so the connection must be to the local host.
+// While the dump is taken, its progress is sent to progress, if not nil,
+// as by MonitorJob. If ctx is done first the dump is aborted.
+func (d *VirDomain) CoreDumpToWriter(ctx context.Context, w io.Writer, flags DomainCoreDumpFlags, progress chan<- DomainJobStats) error {
+	file, err := ioutil.TempFile("", "libvirt-go-core")
+	if err != nil {
+		return err
+	}
+	file.Close()
+	defer os.Remove(file.Name())
+
+	err = d.MonitorJob(ctx, dumpProgressInterval, progress, func() error {
+		return d.CoreDump(file.Name(), flags)
+	})
+	if err != nil {
+		return err
+	}
+	dump, err := os.Open(file.Name())
+	if err != nil {
+		return err
+	}
+	defer dump.Close()
+	_, err = io.Copy(w, dump)
+	return err
+}
+
+// MemoryPeek reads size bytes of the memory of the domain at start, a
+// guest virtual or physical address depending on whether VIR_MEMORY_VIRTUAL
+// or VIR_MEMORY_PHYSICAL is given; exactly one of them is required. Remote
+// connections limit size to 64KiB.
+func (d *VirDomain) MemoryPeek(start uint64, size uint64, flags DomainMemoryFlags) ([]byte, error) {
+	buffer := make([]byte, size)
+	if size == 0 {
+		return buffer, nil
+	}
+	result := C.virDomainMemoryPeek(d.ptr, C.ulonglong(start), C.size_t(size), unsafe.Pointer(&buffer[0]), C.uint(flags))
+	if result == -1 {
+		return nil, GetLastError()
+	}
+	return buffer, nil
+}
+
+// BlockPeek reads size bytes of disk, given by target device or source
+// path, at offset. Remote connections limit size to 64KiB.
+func (d *VirDomain) BlockPeek(disk string, offset uint64, size uint64, flags uint32) ([]byte, error) {
+	cDisk := C.CString(disk)
+	defer C.free(unsafe.Pointer(cDisk))
+	buffer := make([]byte, size)
+	if size == 0 {
+		return buffer, nil
+	}
+	result := C.virDomainBlockPeek(d.ptr, cDisk, C.ulonglong(offset), C.size_t(size), unsafe.Pointer(&buffer[0]), C.uint(flags))
+	if result == -1 {
+		return nil, GetLastError()
+	}
+	return buffer, nil
+}
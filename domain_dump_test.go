@@ -0,0 +1,87 @@
+package libvirt
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestDomainCoreDump(t *testing.T) {
+	dom, conn := buildTestDomain()
+	defer func() {
+		dom.Undefine()
+		dom.Free()
+		if res, _ := conn.CloseConnection(); res != 0 {
+			t.Errorf("CloseConnection() == %d, expected 0", res)
+		}
+	}()
+	if err := dom.Create(); err != nil {
+		t.Fatal(err)
+	}
+	defer dom.Destroy()
+
+	file, err := ioutil.TempFile("", "libvirt-go-test-core")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+	defer os.Remove(file.Name())
+	if err := dom.CoreDump(file.Name(), VIR_DUMP_LIVE); err != nil {
+		t.Fatal(err)
+	}
+	dump, err := ioutil.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dump) == 0 {
+		t.Error("empty core dump")
+	}
+
+	var buf bytes.Buffer
+	if err := dom.CoreDumpToWriter(context.Background(), &buf, VIR_DUMP_LIVE, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), dump) {
+		t.Errorf("CoreDumpToWriter() wrote %d bytes, expected %d", buf.Len(), len(dump))
+	}
+}
+
+func TestDomainBlockPeek(t *testing.T) {
+	dom, conn := buildTestQEMUDomain(testQEMUDisk)
+	defer func() {
+		dom.Undefine()
+		dom.Free()
+		if res, _ := conn.CloseConnection(); res != 0 {
+			t.Errorf("CloseConnection() == %d, expected 0", res)
+		}
+	}()
+
+	magic, err := dom.BlockPeek("vda", 0, 4, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(magic) != "QFI\xfb" {
+		t.Errorf("BlockPeek() == %q, expected qcow2 magic", magic)
+	}
+}
+
+func TestDomainMemoryPeekFlags(t *testing.T) {
+	dom, conn := buildTestDomain()
+	defer func() {
+		dom.Undefine()
+		dom.Free()
+		if res, _ := conn.CloseConnection(); res != 0 {
+			t.Errorf("CloseConnection() == %d, expected 0", res)
+		}
+	}()
+
+	_, err := dom.MemoryPeek(0, 8, VIR_MEMORY_VIRTUAL|VIR_MEMORY_PHYSICAL)
+	if err == nil {
+		t.Fatal("MemoryPeek() with both VIRTUAL and PHYSICAL should have failed")
+	}
+	if virErr, ok := err.(VirError); !ok || virErr.Code != VIR_ERR_INVALID_ARG {
+		t.Errorf("MemoryPeek() error == %v, expected VIR_ERR_INVALID_ARG", err)
+	}
+}
@@ -0,0 +1,224 @@
+package libvirt
+
+/*
+#cgo LDFLAGS: -lvirt
+#include <libvirt/libvirt.h>
+#include <libvirt/virterror.h>
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"context"
+	"time"
+)
+
+// DomainJobStats describes the progress of a long running job such as a
+// save, dump or migration. Times are in milliseconds, amounts in bytes and
+// rates in bytes per second. Fields the hypervisor does not report are left
+// zero.
+type DomainJobStats struct {
+	Type      int // One of VIR_DOMAIN_JOB_*
+	Operation int // One of VIR_DOMAIN_JOB_OPERATION_*
+
+	TimeElapsed    uint64
+	TimeElapsedNet uint64
+	TimeRemaining  uint64
+	Downtime       uint64
+	DowntimeNet    uint64
+	SetupTime      uint64
+
+	DataTotal     uint64
+	DataProcessed uint64
+	DataRemaining uint64
+
+	MemTotal       uint64
+	MemProcessed   uint64
+	MemRemaining   uint64
+	MemConstant    uint64 // Pages
+	MemNormal      uint64 // Pages
+	MemNormalBytes uint64
+	MemBps         uint64
+	MemDirtyRate   uint64 // Pages per second
+	MemPageSize    uint64
+	MemIteration   uint64
+
+	DiskTotal     uint64
+	DiskProcessed uint64
+	DiskRemaining uint64
+	DiskBps       uint64
+
+	CompressionCache       uint64
+	CompressionBytes       uint64
+	CompressionPages       uint64
+	CompressionCacheMisses uint64
+	CompressionOverflow    uint64
+
+	AutoConvergeThrottle int // Percentage the guest CPUs are throttled by
+}
+
+// Active reports whether the stats describe a job that is still running.
+func (s *DomainJobStats) Active() bool {
+	return s.Type == VIR_DOMAIN_JOB_BOUNDED || s.Type == VIR_DOMAIN_JOB_UNBOUNDED
+}
+
+func (s *DomainJobStats) loadFromParams(params VirTypedParameters) {
+	ullongs := map[string]*uint64{
+		VIR_DOMAIN_JOB_TIME_ELAPSED:             &s.TimeElapsed,
+		VIR_DOMAIN_JOB_TIME_ELAPSED_NET:         &s.TimeElapsedNet,
+		VIR_DOMAIN_JOB_TIME_REMAINING:           &s.TimeRemaining,
+		VIR_DOMAIN_JOB_DOWNTIME:                 &s.Downtime,
+		VIR_DOMAIN_JOB_DOWNTIME_NET:             &s.DowntimeNet,
+		VIR_DOMAIN_JOB_SETUP_TIME:               &s.SetupTime,
+		VIR_DOMAIN_JOB_DATA_TOTAL:               &s.DataTotal,
+		VIR_DOMAIN_JOB_DATA_PROCESSED:           &s.DataProcessed,
+		VIR_DOMAIN_JOB_DATA_REMAINING:           &s.DataRemaining,
+		VIR_DOMAIN_JOB_MEMORY_TOTAL:             &s.MemTotal,
+		VIR_DOMAIN_JOB_MEMORY_PROCESSED:         &s.MemProcessed,
+		VIR_DOMAIN_JOB_MEMORY_REMAINING:         &s.MemRemaining,
+		VIR_DOMAIN_JOB_MEMORY_CONSTANT:          &s.MemConstant,
+		VIR_DOMAIN_JOB_MEMORY_NORMAL:            &s.MemNormal,
+		VIR_DOMAIN_JOB_MEMORY_NORMAL_BYTES:      &s.MemNormalBytes,
+		VIR_DOMAIN_JOB_MEMORY_BPS:               &s.MemBps,
+		VIR_DOMAIN_JOB_MEMORY_DIRTY_RATE:        &s.MemDirtyRate,
+		VIR_DOMAIN_JOB_MEMORY_PAGE_SIZE:         &s.MemPageSize,
+		VIR_DOMAIN_JOB_MEMORY_ITERATION:         &s.MemIteration,
+		VIR_DOMAIN_JOB_DISK_TOTAL:               &s.DiskTotal,
+		VIR_DOMAIN_JOB_DISK_PROCESSED:           &s.DiskProcessed,
+		VIR_DOMAIN_JOB_DISK_REMAINING:           &s.DiskRemaining,
+		VIR_DOMAIN_JOB_DISK_BPS:                 &s.DiskBps,
+		VIR_DOMAIN_JOB_COMPRESSION_CACHE:        &s.CompressionCache,
+		VIR_DOMAIN_JOB_COMPRESSION_BYTES:        &s.CompressionBytes,
+		VIR_DOMAIN_JOB_COMPRESSION_PAGES:        &s.CompressionPages,
+		VIR_DOMAIN_JOB_COMPRESSION_CACHE_MISSES: &s.CompressionCacheMisses,
+		VIR_DOMAIN_JOB_COMPRESSION_OVERFLOW:     &s.CompressionOverflow,
+	}
+	ints := map[string]*int{
+		VIR_DOMAIN_JOB_OPERATION:              &s.Operation,
+		VIR_DOMAIN_JOB_AUTO_CONVERGE_THROTTLE: &s.AutoConvergeThrottle,
+	}
+
+	for _, param := range params {
+		switch value := param.Value.(type) {
+		case uint64:
+			if field, ok := ullongs[param.Name]; ok {
+				*field = value
+			}
+		case int:
+			if field, ok := ints[param.Name]; ok {
+				*field = value
+			}
+		}
+	}
+}
+
+// GetJobInfo returns the basic progress of the active job. Only Type, the
+// times and the data, memory and disk amounts are filled in.
+func (d *VirDomain) GetJobInfo() (DomainJobStats, error) {
+	var cInfo C.virDomainJobInfo
+	result := C.virDomainGetJobInfo(d.ptr, &cInfo)
+	if result == -1 {
+		return DomainJobStats{}, GetLastError()
+	}
+	return DomainJobStats{
+		Type:          int(cInfo._type),
+		TimeElapsed:   uint64(cInfo.timeElapsed),
+		TimeRemaining: uint64(cInfo.timeRemaining),
+		DataTotal:     uint64(cInfo.dataTotal),
+		DataProcessed: uint64(cInfo.dataProcessed),
+		DataRemaining: uint64(cInfo.dataRemaining),
+		MemTotal:      uint64(cInfo.memTotal),
+		MemProcessed:  uint64(cInfo.memProcessed),
+		MemRemaining:  uint64(cInfo.memRemaining),
+		DiskTotal:     uint64(cInfo.fileTotal),
+		DiskProcessed: uint64(cInfo.fileProcessed),
+		DiskRemaining: uint64(cInfo.fileRemaining),
+	}, nil
+}
+
+// GetJobStats returns the detailed progress of the active job, or of the
+// last completed one with VIR_DOMAIN_JOB_STATS_COMPLETED.
+func (d *VirDomain) GetJobStats(flags uint32) (DomainJobStats, error) {
+	var (
+		jobType  C.int
+		cParams  C.virTypedParameterPtr
+		cnParams C.int
+	)
+	result := C.virDomainGetJobStats(d.ptr, &jobType, &cParams, &cnParams, C.uint(flags))
+	if result == -1 {
+		return DomainJobStats{}, GetLastError()
+	}
+	defer C.virTypedParamsFree(cParams, cnParams)
+
+	var params VirTypedParameters
+	params.loadFromCPtr(cParams, int(cnParams))
+	stats := DomainJobStats{Type: int(jobType)}
+	stats.loadFromParams(params)
+	return stats, nil
+}
+
+// MonitorJob calls job, which should start a long running operation on the
+// domain such as Save or CoreDump and block until it is over, and polls
+// GetJobStats every interval while it runs. The stats of the running job
+// are sent to progress, if not nil; an update is dropped when the receiver
+// is not ready for it. progress is left open, nothing is sent to it once
+// MonitorJob has returned.
+//
+// The error returned by job is passed through. If ctx is done first, the
+// job is cancelled with AbortJob and ctx.Err() is returned once job has
+// returned, unless it succeeded anyway. AbortJob is retried every interval
+// while the job has not started yet; if it fails on a running job, its
+// error is returned at once and the job is left running.
+func (d *VirDomain) MonitorJob(ctx context.Context, interval time.Duration, progress chan<- DomainJobStats, job func() error) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- job()
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	cancelled := ctx.Done()
+	aborting, aborted := false, false
+	abort := func() error {
+		if err := d.AbortJob(); err != nil {
+			if stats, statsErr := d.GetJobStats(0); statsErr == nil && stats.Active() {
+				return err
+			}
+			// The job has not started yet, try again on the next tick
+			return nil
+		}
+		aborted = true
+		return nil
+	}
+
+	for {
+		select {
+		case err := <-done:
+			if err != nil && aborted {
+				return ctx.Err()
+			}
+			return err
+		case <-cancelled:
+			cancelled = nil
+			aborting = true
+			if err := abort(); err != nil {
+				return err
+			}
+		case <-ticker.C:
+			if aborting && !aborted {
+				if err := abort(); err != nil {
+					return err
+				}
+			}
+			stats, err := d.GetJobStats(0)
+			if err != nil || !stats.Active() || progress == nil {
+				continue
+			}
+			select {
+			case progress <- stats:
+			default:
+			}
+		}
+	}
+}
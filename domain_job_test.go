@@ -0,0 +1,116 @@
+package libvirt
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDomainJobStatsLoadFromParams(t *testing.T) {
+	stats := DomainJobStats{Type: VIR_DOMAIN_JOB_UNBOUNDED}
+	stats.loadFromParams(VirTypedParameters{
+		{VIR_DOMAIN_JOB_OPERATION, VIR_DOMAIN_JOB_OPERATION_MIGRATION_OUT},
+		{VIR_DOMAIN_JOB_TIME_ELAPSED, uint64(1500)},
+		{VIR_DOMAIN_JOB_DATA_TOTAL, uint64(4096)},
+		{VIR_DOMAIN_JOB_DATA_PROCESSED, uint64(1024)},
+		{VIR_DOMAIN_JOB_MEMORY_DIRTY_RATE, uint64(300)},
+		{VIR_DOMAIN_JOB_AUTO_CONVERGE_THROTTLE, 20},
+		{"unknown_field", uint64(1)},
+		{VIR_DOMAIN_JOB_DOWNTIME, "wrong type"},
+	})
+	expected := DomainJobStats{
+		Type:                 VIR_DOMAIN_JOB_UNBOUNDED,
+		Operation:            VIR_DOMAIN_JOB_OPERATION_MIGRATION_OUT,
+		TimeElapsed:          1500,
+		DataTotal:            4096,
+		DataProcessed:        1024,
+		MemDirtyRate:         300,
+		AutoConvergeThrottle: 20,
+	}
+	if stats != expected {
+		t.Errorf("got %+v, expected %+v", stats, expected)
+	}
+	if !stats.Active() {
+		t.Error("expected unbounded job to be active")
+	}
+}
+
+func TestDomainGetJobStats(t *testing.T) {
+	dom, conn := buildTestQEMUDomain("")
+	defer func() {
+		dom.Destroy()
+		dom.Undefine()
+		dom.Free()
+		if res, _ := conn.CloseConnection(); res != 0 {
+			t.Errorf("CloseConnection() == %d, expected 0", res)
+		}
+	}()
+
+	if err := dom.Create(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	info, err := dom.GetJobInfo()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if info.Type != VIR_DOMAIN_JOB_NONE {
+		t.Errorf("GetJobInfo().Type == %d, expected %d", info.Type, VIR_DOMAIN_JOB_NONE)
+	}
+
+	stats, err := dom.GetJobStats(0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if stats.Active() {
+		t.Errorf("unexpected active job: %+v", stats)
+	}
+}
+
+func TestDomainMonitorJob(t *testing.T) {
+	dom, conn := buildTestDomain()
+	defer func() {
+		dom.Undefine()
+		dom.Free()
+		if res, _ := conn.CloseConnection(); res != 0 {
+			t.Errorf("CloseConnection() == %d, expected 0", res)
+		}
+	}()
+
+	jobErr := errors.New("job failed")
+	progress := make(chan DomainJobStats)
+	for i := 0; i < 2; i++ {
+		// The channel stays open for reuse
+		err := dom.MonitorJob(context.Background(), time.Millisecond, progress, func() error {
+			time.Sleep(10 * time.Millisecond)
+			return jobErr
+		})
+		if err != jobErr {
+			t.Errorf("MonitorJob() == %v, expected %v", err, jobErr)
+		}
+	}
+	close(progress)
+
+	// The test driver cannot abort jobs, so the job runs to its end and its
+	// own result is returned.
+	for _, result := range []error{nil, jobErr} {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		finished := false
+		err := dom.MonitorJob(ctx, time.Millisecond, nil, func() error {
+			time.Sleep(50 * time.Millisecond)
+			finished = true
+			return result
+		})
+		cancel()
+		if err != result {
+			t.Errorf("MonitorJob() == %v, expected %v", err, result)
+		}
+		if !finished {
+			t.Error("MonitorJob returned before the job did")
+		}
+	}
+}
@@ -6,7 +6,15 @@ import (
 	"time"
 )
 
-func buildTestQEMUDomain() (VirDomain, VirConnection) {
+// testQEMUDisk attaches the shared test image as vda.
+const testQEMUDisk = `<disk type='file' device='disk'>
+			<driver name='qemu' type='qcow2'/>
+			<source file='/var/lib/libvirt/images/test-src.qcow2'/>
+			<target dev='vda' bus='virtio'/>
+		</disk>`
+
+// buildTestQEMUDomain defines a qemu domain with the given extra devices.
+func buildTestQEMUDomain(devices string) (VirDomain, VirConnection) {
 	conn := buildTestQEMUConnection()
 	dom, err := conn.DomainDefineXML(`<domain type="qemu">
 		<name>` + strings.Replace(time.Now().String(), " ", "_", -1) + `</name>
@@ -14,6 +22,7 @@ func buildTestQEMUDomain() (VirDomain, VirConnection) {
 		<os>
 			<type>hvm</type>
 		</os>
+		<devices>` + devices + `</devices>
 	</domain>`)
 	if err != nil {
 		panic(err)
@@ -66,6 +75,23 @@ func buildTransientTestDomain() (VirDomain, VirConnection) {
 	return dom, conn
 }
 
+// buildTestQEMURunningDomain starts a transient qemu domain with the given
+// extra devices. It has no guest agent.
+func buildTestQEMURunningDomain(t *testing.T, conn VirConnection, name string, devices string) VirDomain {
+	dom, err := conn.DomainCreateXML(`<domain type="qemu">
+		<name>`+name+`</name>
+		<memory unit="KiB">8192</memory>
+		<os>
+			<type>hvm</type>
+		</os>
+		<devices>`+devices+`</devices>
+	</domain>`, VIR_DOMAIN_NONE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dom
+}
+
 func TestUndefineDomain(t *testing.T) {
 	dom, conn := buildTestDomain()
 	defer func() {
@@ -730,7 +756,7 @@ func TestDomainPinVcpu(t *testing.T) {
 }
 
 func TestQemuMonitorCommand(t *testing.T) {
-	dom, conn := buildTestQEMUDomain()
+	dom, conn := buildTestQEMUDomain("")
 	defer func() {
 		dom.Destroy()
 		dom.Undefine()
@@ -757,7 +783,7 @@ func TestQemuMonitorCommand(t *testing.T) {
 }
 
 func TestDomainCreateWithFlags(t *testing.T) {
-	dom, conn := buildTestQEMUDomain()
+	dom, conn := buildTestQEMUDomain("")
 	defer func() {
 		dom.Destroy()
 		dom.Undefine()
@@ -779,3 +805,93 @@ func TestDomainCreateWithFlags(t *testing.T) {
 		}
 	}
 }
+
+func TestDomainResetInjectNMI(t *testing.T) {
+	conn := buildTestQEMUConnection()
+	defer func() {
+		if res, _ := conn.CloseConnection(); res != 0 {
+			t.Errorf("CloseConnection() == %d, expected 0", res)
+		}
+	}()
+	dom := buildTestQEMURunningDomain(t, conn, "test-reset-nmi", "")
+	defer func() {
+		dom.Destroy()
+		dom.Free()
+	}()
+
+	if err := dom.InjectNMI(0); err != nil {
+		t.Error(err)
+	}
+	if err := dom.Reset(0); err != nil {
+		t.Error(err)
+	}
+	if active, _ := dom.IsActive(); !active {
+		t.Error("domain not active after Reset()")
+	}
+	// There is no guest agent to suspend the domain
+	if err := dom.PMSuspendForDuration(VIR_NODE_SUSPEND_TARGET_MEM, 0, 0); err == nil {
+		t.Error("expected error suspending a domain without guest agent")
+	}
+}
+
+func TestDomainGetOSTypeMaxMemoryMaxVcpus(t *testing.T) {
+	dom, conn := buildTestDomain()
+	defer func() {
+		dom.Destroy()
+		dom.Undefine()
+		dom.Free()
+		if res, _ := conn.CloseConnection(); res != 0 {
+			t.Errorf("CloseConnection() == %d, expected 0", res)
+		}
+	}()
+	if err := dom.Create(); err != nil {
+		t.Fatal(err)
+	}
+
+	if osType, err := dom.GetOSType(); err != nil || osType != "hvm" {
+		t.Errorf("GetOSType() == %q, %v, expected hvm", osType, err)
+	}
+	if memory, err := dom.GetMaxMemory(); err != nil || memory != 8192 {
+		t.Errorf("GetMaxMemory() == %d, %v, expected 8192", memory, err)
+	}
+	if vcpus, err := dom.GetMaxVcpus(); err != nil || vcpus != 1 {
+		t.Errorf("GetMaxVcpus() == %d, %v, expected 1", vcpus, err)
+	}
+}
+
+func TestDomainEmulatorAndVcpuPinInfo(t *testing.T) {
+	dom, conn := buildTestQEMUDomain("")
+	defer func() {
+		dom.Undefine()
+		dom.Free()
+		if res, _ := conn.CloseConnection(); res != 0 {
+			t.Errorf("CloseConnection() == %d, expected 0", res)
+		}
+	}()
+	ni, err := conn.GetNodeInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := dom.PinEmulator([]uint32{0}, VIR_DOMAIN_AFFECT_CONFIG, ni.GetMaxCPUs()); err != nil {
+		t.Fatal(err)
+	}
+	cpuMap, err := dom.GetEmulatorPinInfo(ni.GetMaxCPUs(), VIR_DOMAIN_AFFECT_CONFIG)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cpuMap) != 1 || cpuMap[0] != 0 {
+		t.Errorf("GetEmulatorPinInfo() == %v, expected [0]", cpuMap)
+	}
+
+	if err := dom.PinVcpuFlags(0, []uint32{0}, VIR_DOMAIN_AFFECT_CONFIG, ni.GetMaxCPUs()); err != nil {
+		t.Fatal(err)
+	}
+	vcpuMaps, err := dom.GetVcpuPinInfo(ni.GetMaxCPUs(), VIR_DOMAIN_AFFECT_CONFIG)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vcpuMaps) != 1 || len(vcpuMaps[0]) != 1 || vcpuMaps[0][0] != 0 {
+		t.Errorf("GetVcpuPinInfo() == %v, expected [[0]]", vcpuMaps)
+	}
+}
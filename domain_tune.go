@@ -0,0 +1,497 @@
+package libvirt
+
+/*
+#cgo LDFLAGS: -lvirt
+#include <libvirt/libvirt.h>
+#include <libvirt/virterror.h>
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// BlockIoTune holds the I/O limits of a disk. Rates are in bytes or
+// operations per second, zero meaning unlimited. The _Max variants allow
+// bursts above the base rate for up to the matching _MaxLength seconds.
+// Only the fields whose Set flag is true are changed by SetBlockIoTune.
+type BlockIoTune struct {
+	TotalBytesSecSet          bool
+	TotalBytesSec             uint64
+	ReadBytesSecSet           bool
+	ReadBytesSec              uint64
+	WriteBytesSecSet          bool
+	WriteBytesSec             uint64
+	TotalIopsSecSet           bool
+	TotalIopsSec              uint64
+	ReadIopsSecSet            bool
+	ReadIopsSec               uint64
+	WriteIopsSecSet           bool
+	WriteIopsSec              uint64
+	TotalBytesSecMaxSet       bool
+	TotalBytesSecMax          uint64
+	ReadBytesSecMaxSet        bool
+	ReadBytesSecMax           uint64
+	WriteBytesSecMaxSet       bool
+	WriteBytesSecMax          uint64
+	TotalIopsSecMaxSet        bool
+	TotalIopsSecMax           uint64
+	ReadIopsSecMaxSet         bool
+	ReadIopsSecMax            uint64
+	WriteIopsSecMaxSet        bool
+	WriteIopsSecMax           uint64
+	TotalBytesSecMaxLengthSet bool
+	TotalBytesSecMaxLength    uint64
+	ReadBytesSecMaxLengthSet  bool
+	ReadBytesSecMaxLength     uint64
+	WriteBytesSecMaxLengthSet bool
+	WriteBytesSecMaxLength    uint64
+	TotalIopsSecMaxLengthSet  bool
+	TotalIopsSecMaxLength     uint64
+	ReadIopsSecMaxLengthSet   bool
+	ReadIopsSecMaxLength      uint64
+	WriteIopsSecMaxLengthSet  bool
+	WriteIopsSecMaxLength     uint64
+	SizeIopsSecSet            bool
+	SizeIopsSec               uint64 // Size in bytes counted as one operation
+	GroupName                 string // Throttle group shared with other disks
+}
+
+func (t *BlockIoTune) fields() []typedParamField {
+	return []typedParamField{
+		{VIR_DOMAIN_BLOCK_IOTUNE_TOTAL_BYTES_SEC, &t.TotalBytesSecSet, &t.TotalBytesSec},
+		{VIR_DOMAIN_BLOCK_IOTUNE_READ_BYTES_SEC, &t.ReadBytesSecSet, &t.ReadBytesSec},
+		{VIR_DOMAIN_BLOCK_IOTUNE_WRITE_BYTES_SEC, &t.WriteBytesSecSet, &t.WriteBytesSec},
+		{VIR_DOMAIN_BLOCK_IOTUNE_TOTAL_IOPS_SEC, &t.TotalIopsSecSet, &t.TotalIopsSec},
+		{VIR_DOMAIN_BLOCK_IOTUNE_READ_IOPS_SEC, &t.ReadIopsSecSet, &t.ReadIopsSec},
+		{VIR_DOMAIN_BLOCK_IOTUNE_WRITE_IOPS_SEC, &t.WriteIopsSecSet, &t.WriteIopsSec},
+		{VIR_DOMAIN_BLOCK_IOTUNE_TOTAL_BYTES_SEC_MAX, &t.TotalBytesSecMaxSet, &t.TotalBytesSecMax},
+		{VIR_DOMAIN_BLOCK_IOTUNE_READ_BYTES_SEC_MAX, &t.ReadBytesSecMaxSet, &t.ReadBytesSecMax},
+		{VIR_DOMAIN_BLOCK_IOTUNE_WRITE_BYTES_SEC_MAX, &t.WriteBytesSecMaxSet, &t.WriteBytesSecMax},
+		{VIR_DOMAIN_BLOCK_IOTUNE_TOTAL_IOPS_SEC_MAX, &t.TotalIopsSecMaxSet, &t.TotalIopsSecMax},
+		{VIR_DOMAIN_BLOCK_IOTUNE_READ_IOPS_SEC_MAX, &t.ReadIopsSecMaxSet, &t.ReadIopsSecMax},
+		{VIR_DOMAIN_BLOCK_IOTUNE_WRITE_IOPS_SEC_MAX, &t.WriteIopsSecMaxSet, &t.WriteIopsSecMax},
+		{VIR_DOMAIN_BLOCK_IOTUNE_TOTAL_BYTES_SEC_MAX_LENGTH, &t.TotalBytesSecMaxLengthSet, &t.TotalBytesSecMaxLength},
+		{VIR_DOMAIN_BLOCK_IOTUNE_READ_BYTES_SEC_MAX_LENGTH, &t.ReadBytesSecMaxLengthSet, &t.ReadBytesSecMaxLength},
+		{VIR_DOMAIN_BLOCK_IOTUNE_WRITE_BYTES_SEC_MAX_LENGTH, &t.WriteBytesSecMaxLengthSet, &t.WriteBytesSecMaxLength},
+		{VIR_DOMAIN_BLOCK_IOTUNE_TOTAL_IOPS_SEC_MAX_LENGTH, &t.TotalIopsSecMaxLengthSet, &t.TotalIopsSecMaxLength},
+		{VIR_DOMAIN_BLOCK_IOTUNE_READ_IOPS_SEC_MAX_LENGTH, &t.ReadIopsSecMaxLengthSet, &t.ReadIopsSecMaxLength},
+		{VIR_DOMAIN_BLOCK_IOTUNE_WRITE_IOPS_SEC_MAX_LENGTH, &t.WriteIopsSecMaxLengthSet, &t.WriteIopsSecMaxLength},
+		{VIR_DOMAIN_BLOCK_IOTUNE_SIZE_IOPS_SEC, &t.SizeIopsSecSet, &t.SizeIopsSec},
+		{VIR_DOMAIN_BLOCK_IOTUNE_GROUP_NAME, nil, &t.GroupName},
+	}
+}
+
+// SetBlockIoTune changes the I/O limits of disk, of the running domain
+// and/or its persistent configuration depending on the
+// VIR_DOMAIN_AFFECT_* flags.
+func (d *VirDomain) SetBlockIoTune(disk string, tune *BlockIoTune, flags uint32) error {
+	cDisk := C.CString(disk)
+	defer C.free(unsafe.Pointer(cDisk))
+
+	params := encodeTypedParams(tune.fields())
+	cParams, cnParams, err := params.loadToCPtr()
+	if err != nil {
+		return err
+	}
+	defer C.virTypedParamsFree(cParams, cnParams)
+
+	result := C.virDomainSetBlockIoTune(d.ptr, cDisk, cParams, cnParams, C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
+
+func (d *VirDomain) GetBlockIoTune(disk string, flags uint32) (*BlockIoTune, error) {
+	cDisk := C.CString(disk)
+	defer C.free(unsafe.Pointer(cDisk))
+
+	params, err := getTypedParams(func(cParams C.virTypedParameterPtr, cnParams *C.int) C.int {
+		return C.virDomainGetBlockIoTune(d.ptr, cDisk, cParams, cnParams, C.uint(flags))
+	})
+	if err != nil {
+		return nil, err
+	}
+	tune := &BlockIoTune{}
+	decodeTypedParams(tune.fields(), params)
+	return tune, nil
+}
+
+// InterfaceBandwidth holds the QoS of a network interface. Averages,
+// peaks and floors are in kilobytes per second, bursts in kilobytes. An
+// average of zero removes the limit in that direction. Only the fields
+// whose Set flag is true are changed by SetInterfaceParameters.
+type InterfaceBandwidth struct {
+	InboundAverageSet  bool
+	InboundAverage     uint
+	InboundPeakSet     bool
+	InboundPeak        uint
+	InboundBurstSet    bool
+	InboundBurst       uint
+	InboundFloorSet    bool
+	InboundFloor       uint // Guaranteed rate, only on networks with a bandwidth
+	OutboundAverageSet bool
+	OutboundAverage    uint
+	OutboundPeakSet    bool
+	OutboundPeak       uint
+	OutboundBurstSet   bool
+	OutboundBurst      uint
+}
+
+func (b *InterfaceBandwidth) fields() []typedParamField {
+	return []typedParamField{
+		{VIR_DOMAIN_BANDWIDTH_IN_AVERAGE, &b.InboundAverageSet, &b.InboundAverage},
+		{VIR_DOMAIN_BANDWIDTH_IN_PEAK, &b.InboundPeakSet, &b.InboundPeak},
+		{VIR_DOMAIN_BANDWIDTH_IN_BURST, &b.InboundBurstSet, &b.InboundBurst},
+		{VIR_DOMAIN_BANDWIDTH_IN_FLOOR, &b.InboundFloorSet, &b.InboundFloor},
+		{VIR_DOMAIN_BANDWIDTH_OUT_AVERAGE, &b.OutboundAverageSet, &b.OutboundAverage},
+		{VIR_DOMAIN_BANDWIDTH_OUT_PEAK, &b.OutboundPeakSet, &b.OutboundPeak},
+		{VIR_DOMAIN_BANDWIDTH_OUT_BURST, &b.OutboundBurstSet, &b.OutboundBurst},
+	}
+}
+
+// GetInterfaceParameters returns the QoS of the interface device, given by
+// target device name or MAC address.
+func (d *VirDomain) GetInterfaceParameters(device string, flags uint32) (*InterfaceBandwidth, error) {
+	cDevice := C.CString(device)
+	defer C.free(unsafe.Pointer(cDevice))
+
+	params, err := getTypedParams(func(cParams C.virTypedParameterPtr, cnParams *C.int) C.int {
+		return C.virDomainGetInterfaceParameters(d.ptr, cDevice, cParams, cnParams, C.uint(flags))
+	})
+	if err != nil {
+		return nil, err
+	}
+	bandwidth := &InterfaceBandwidth{}
+	decodeTypedParams(bandwidth.fields(), params)
+	return bandwidth, nil
+}
+
+// SetInterfaceParameters changes the QoS of the interface device, of the
+// running domain and/or its persistent configuration depending on the
+// VIR_DOMAIN_AFFECT_* flags.
+func (d *VirDomain) SetInterfaceParameters(device string, bandwidth *InterfaceBandwidth, flags uint32) error {
+	cDevice := C.CString(device)
+	defer C.free(unsafe.Pointer(cDevice))
+
+	params := encodeTypedParams(bandwidth.fields())
+	cParams, cnParams, err := params.loadToCPtr()
+	if err != nil {
+		return err
+	}
+	defer C.virTypedParamsFree(cParams, cnParams)
+
+	result := C.virDomainSetInterfaceParameters(d.ptr, cDevice, cParams, cnParams, C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
+
+// DomainSchedulerParameters holds the CPU scheduler tunables of a domain.
+// Periods are in microseconds; a negative quota means unlimited. Only the
+// fields whose Set flag is true are changed by SetSchedulerParameters.
+type DomainSchedulerParameters struct {
+	Type              string // Scheduler name, filled in by GetSchedulerParameters
+	CpuSharesSet      bool
+	CpuShares         uint64
+	VcpuPeriodSet     bool
+	VcpuPeriod        uint64
+	VcpuQuotaSet      bool
+	VcpuQuota         int64
+	EmulatorPeriodSet bool
+	EmulatorPeriod    uint64
+	EmulatorQuotaSet  bool
+	EmulatorQuota     int64
+}
+
+func (p *DomainSchedulerParameters) fields() []typedParamField {
+	return []typedParamField{
+		{VIR_DOMAIN_SCHEDULER_CPU_SHARES, &p.CpuSharesSet, &p.CpuShares},
+		{VIR_DOMAIN_SCHEDULER_VCPU_PERIOD, &p.VcpuPeriodSet, &p.VcpuPeriod},
+		{VIR_DOMAIN_SCHEDULER_VCPU_QUOTA, &p.VcpuQuotaSet, &p.VcpuQuota},
+		{VIR_DOMAIN_SCHEDULER_EMULATOR_PERIOD, &p.EmulatorPeriodSet, &p.EmulatorPeriod},
+		{VIR_DOMAIN_SCHEDULER_EMULATOR_QUOTA, &p.EmulatorQuotaSet, &p.EmulatorQuota},
+	}
+}
+
+func (d *VirDomain) GetSchedulerParameters(flags uint32) (*DomainSchedulerParameters, error) {
+	var cnParams C.int
+	cType := C.virDomainGetSchedulerType(d.ptr, &cnParams)
+	if cType == nil {
+		return nil, GetLastError()
+	}
+	sched := &DomainSchedulerParameters{Type: C.GoString(cType)}
+	C.free(unsafe.Pointer(cType))
+
+	params, err := getTypedParams(func(cParams C.virTypedParameterPtr, nParams *C.int) C.int {
+		// The count comes from virDomainGetSchedulerType, which must not
+		// be asked again as its string would leak.
+		if cParams == nil {
+			*nParams = cnParams
+			return 0
+		}
+		return C.virDomainGetSchedulerParametersFlags(d.ptr, cParams, nParams, C.uint(flags))
+	})
+	if err != nil {
+		return nil, err
+	}
+	decodeTypedParams(sched.fields(), params)
+	return sched, nil
+}
+
+// SetSchedulerParameters changes the CPU scheduler tunables of the running
+// domain and/or its persistent configuration depending on the
+// VIR_DOMAIN_AFFECT_* flags. Type is ignored.
+func (d *VirDomain) SetSchedulerParameters(sched *DomainSchedulerParameters, flags uint32) error {
+	params := encodeTypedParams(sched.fields())
+	cParams, cnParams, err := params.loadToCPtr()
+	if err != nil {
+		return err
+	}
+	defer C.virTypedParamsFree(cParams, cnParams)
+
+	result := C.virDomainSetSchedulerParametersFlags(d.ptr, cParams, cnParams, C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
+
+// DomainMemoryParameters holds the memory limits of a domain, in KiB.
+// VIR_DOMAIN_MEMORY_PARAM_UNLIMITED means no limit. Only the fields whose
+// Set flag is true are changed by SetMemoryParameters.
+type DomainMemoryParameters struct {
+	HardLimitSet     bool
+	HardLimit        uint64
+	SoftLimitSet     bool
+	SoftLimit        uint64
+	MinGuaranteeSet  bool
+	MinGuarantee     uint64
+	SwapHardLimitSet bool
+	SwapHardLimit    uint64 // Memory plus swap
+}
+
+func (p *DomainMemoryParameters) fields() []typedParamField {
+	return []typedParamField{
+		{VIR_DOMAIN_MEMORY_HARD_LIMIT, &p.HardLimitSet, &p.HardLimit},
+		{VIR_DOMAIN_MEMORY_SOFT_LIMIT, &p.SoftLimitSet, &p.SoftLimit},
+		{VIR_DOMAIN_MEMORY_MIN_GUARANTEE, &p.MinGuaranteeSet, &p.MinGuarantee},
+		{VIR_DOMAIN_MEMORY_SWAP_HARD_LIMIT, &p.SwapHardLimitSet, &p.SwapHardLimit},
+	}
+}
+
+func (d *VirDomain) GetMemoryParameters(flags uint32) (*DomainMemoryParameters, error) {
+	params, err := getTypedParams(func(cParams C.virTypedParameterPtr, cnParams *C.int) C.int {
+		return C.virDomainGetMemoryParameters(d.ptr, cParams, cnParams, C.uint(flags))
+	})
+	if err != nil {
+		return nil, err
+	}
+	mem := &DomainMemoryParameters{}
+	decodeTypedParams(mem.fields(), params)
+	return mem, nil
+}
+
+// SetMemoryParameters changes the memory limits of the running domain
+// and/or its persistent configuration depending on the VIR_DOMAIN_AFFECT_*
+// flags.
+func (d *VirDomain) SetMemoryParameters(mem *DomainMemoryParameters, flags uint32) error {
+	params := encodeTypedParams(mem.fields())
+	cParams, cnParams, err := params.loadToCPtr()
+	if err != nil {
+		return err
+	}
+	defer C.virTypedParamsFree(cParams, cnParams)
+
+	result := C.virDomainSetMemoryParameters(d.ptr, cParams, cnParams, C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
+
+// BlkioDevice is a per-device blkio tunable: a weight or a throttle in
+// bytes or operations per second, depending on the list it is in.
+type BlkioDevice struct {
+	Path  string
+	Value uint64
+}
+
+// formatBlkioDevices encodes devices the way libvirt expects them, as a
+// comma separated list of path and value pairs.
+func formatBlkioDevices(devices []BlkioDevice) string {
+	parts := make([]string, 0, 2*len(devices))
+	for _, device := range devices {
+		parts = append(parts, device.Path, strconv.FormatUint(device.Value, 10))
+	}
+	return strings.Join(parts, ",")
+}
+
+func parseBlkioDevices(s string) ([]BlkioDevice, error) {
+	if s == "" {
+		return nil, nil
+	}
+	parts := strings.Split(s, ",")
+	if len(parts)%2 != 0 {
+		return nil, fmt.Errorf("Malformed blkio device list: %s", s)
+	}
+	devices := make([]BlkioDevice, 0, len(parts)/2)
+	for i := 0; i < len(parts); i += 2 {
+		value, err := strconv.ParseUint(parts[i+1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Malformed blkio device list: %s", s)
+		}
+		devices = append(devices, BlkioDevice{parts[i], value})
+	}
+	return devices, nil
+}
+
+// DomainBlkioParameters holds the block I/O tunables of a domain. Weight
+// ranges from 100 to 1000. The device lists are only sent when not empty
+// and then replace the settings of the devices they name; a device is
+// reset by giving it a value of zero.
+type DomainBlkioParameters struct {
+	WeightSet        bool
+	Weight           uint
+	DeviceWeights    []BlkioDevice
+	DeviceReadIops   []BlkioDevice
+	DeviceWriteIops  []BlkioDevice
+	DeviceReadBytes  []BlkioDevice
+	DeviceWriteBytes []BlkioDevice
+}
+
+func (p *DomainBlkioParameters) devices() map[string]*[]BlkioDevice {
+	return map[string]*[]BlkioDevice{
+		VIR_DOMAIN_BLKIO_DEVICE_WEIGHT:     &p.DeviceWeights,
+		VIR_DOMAIN_BLKIO_DEVICE_READ_IOPS:  &p.DeviceReadIops,
+		VIR_DOMAIN_BLKIO_DEVICE_WRITE_IOPS: &p.DeviceWriteIops,
+		VIR_DOMAIN_BLKIO_DEVICE_READ_BPS:   &p.DeviceReadBytes,
+		VIR_DOMAIN_BLKIO_DEVICE_WRITE_BPS:  &p.DeviceWriteBytes,
+	}
+}
+
+func (p *DomainBlkioParameters) typedParams() VirTypedParameters {
+	params := encodeTypedParams([]typedParamField{
+		{VIR_DOMAIN_BLKIO_WEIGHT, &p.WeightSet, &p.Weight},
+	})
+	devices := p.devices()
+	for _, name := range []string{
+		VIR_DOMAIN_BLKIO_DEVICE_WEIGHT,
+		VIR_DOMAIN_BLKIO_DEVICE_READ_IOPS,
+		VIR_DOMAIN_BLKIO_DEVICE_WRITE_IOPS,
+		VIR_DOMAIN_BLKIO_DEVICE_READ_BPS,
+		VIR_DOMAIN_BLKIO_DEVICE_WRITE_BPS,
+	} {
+		if list := *devices[name]; len(list) > 0 {
+			params = append(params, VirTypedParameter{name, formatBlkioDevices(list)})
+		}
+	}
+	return params
+}
+
+func (p *DomainBlkioParameters) loadFromParams(params VirTypedParameters) error {
+	decodeTypedParams([]typedParamField{
+		{VIR_DOMAIN_BLKIO_WEIGHT, &p.WeightSet, &p.Weight},
+	}, params)
+	devices := p.devices()
+	for _, param := range params {
+		field, ok := devices[param.Name]
+		if !ok {
+			continue
+		}
+		value, ok := param.Value.(string)
+		if !ok {
+			continue
+		}
+		parsed, err := parseBlkioDevices(value)
+		if err != nil {
+			return err
+		}
+		*field = parsed
+	}
+	return nil
+}
+
+func (d *VirDomain) GetBlkioParameters(flags uint32) (*DomainBlkioParameters, error) {
+	params, err := getTypedParams(func(cParams C.virTypedParameterPtr, cnParams *C.int) C.int {
+		return C.virDomainGetBlkioParameters(d.ptr, cParams, cnParams, C.uint(flags))
+	})
+	if err != nil {
+		return nil, err
+	}
+	blkio := &DomainBlkioParameters{}
+	if err := blkio.loadFromParams(params); err != nil {
+		return nil, err
+	}
+	return blkio, nil
+}
+
+// SetBlkioParameters changes the block I/O tunables of the running domain
+// and/or its persistent configuration depending on the VIR_DOMAIN_AFFECT_*
+// flags.
+func (d *VirDomain) SetBlkioParameters(blkio *DomainBlkioParameters, flags uint32) error {
+	params := blkio.typedParams()
+	cParams, cnParams, err := params.loadToCPtr()
+	if err != nil {
+		return err
+	}
+	defer C.virTypedParamsFree(cParams, cnParams)
+
+	result := C.virDomainSetBlkioParameters(d.ptr, cParams, cnParams, C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
+
+// DomainNumaParameters holds the NUMA memory placement of a domain.
+// Nodeset is a node list such as "0-1,3" and is only sent when not empty.
+type DomainNumaParameters struct {
+	Nodeset string
+	ModeSet bool
+	Mode    int // One of VIR_DOMAIN_NUMATUNE_MEM_*
+}
+
+func (p *DomainNumaParameters) fields() []typedParamField {
+	return []typedParamField{
+		{VIR_DOMAIN_NUMA_NODESET, nil, &p.Nodeset},
+		{VIR_DOMAIN_NUMA_MODE, &p.ModeSet, &p.Mode},
+	}
+}
+
+func (d *VirDomain) GetNumaParameters(flags uint32) (*DomainNumaParameters, error) {
+	params, err := getTypedParams(func(cParams C.virTypedParameterPtr, cnParams *C.int) C.int {
+		return C.virDomainGetNumaParameters(d.ptr, cParams, cnParams, C.uint(flags))
+	})
+	if err != nil {
+		return nil, err
+	}
+	numa := &DomainNumaParameters{}
+	decodeTypedParams(numa.fields(), params)
+	return numa, nil
+}
+
+// SetNumaParameters changes the NUMA memory placement of the running
+// domain and/or its persistent configuration depending on the
+// VIR_DOMAIN_AFFECT_* flags. The mode of a running domain cannot be
+// changed.
+func (d *VirDomain) SetNumaParameters(numa *DomainNumaParameters, flags uint32) error {
+	params := encodeTypedParams(numa.fields())
+	cParams, cnParams, err := params.loadToCPtr()
+	if err != nil {
+		return err
+	}
+	defer C.virTypedParamsFree(cParams, cnParams)
+
+	result := C.virDomainSetNumaParameters(d.ptr, cParams, cnParams, C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
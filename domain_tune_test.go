@@ -0,0 +1,223 @@
+package libvirt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBlockIoTuneEncoding(t *testing.T) {
+	tune := BlockIoTune{
+		TotalBytesSecSet:      true,
+		TotalBytesSec:         10 << 20,
+		ReadIopsSecSet:        true,
+		ReadIopsSec:           0,
+		ReadIopsSecMaxSet:     true,
+		ReadIopsSecMax:        500,
+		ReadIopsSecMaxLength:  30,
+		GroupName:             "tenant-a",
+		WriteBytesSecMaxSet:   false,
+		WriteBytesSecMax:      1,
+		TotalIopsSecMaxLength: 2,
+	}
+	params := encodeTypedParams(tune.fields())
+	expected := VirTypedParameters{
+		{VIR_DOMAIN_BLOCK_IOTUNE_TOTAL_BYTES_SEC, uint64(10 << 20)},
+		{VIR_DOMAIN_BLOCK_IOTUNE_READ_IOPS_SEC, uint64(0)},
+		{VIR_DOMAIN_BLOCK_IOTUNE_READ_IOPS_SEC_MAX, uint64(500)},
+		{VIR_DOMAIN_BLOCK_IOTUNE_GROUP_NAME, "tenant-a"},
+	}
+	if !reflect.DeepEqual(params, expected) {
+		t.Fatalf("encoded %v, expected %v", params, expected)
+	}
+
+	decodedParams, err := roundTripTypedParams(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded BlockIoTune
+	decodeTypedParams(decoded.fields(), decodedParams)
+	tune.ReadIopsSecMaxLength = 0
+	tune.WriteBytesSecMax = 0
+	tune.TotalIopsSecMaxLength = 0
+	if decoded != tune {
+		t.Errorf("decoded %+v, expected %+v", decoded, tune)
+	}
+}
+
+func TestDomainBlockIoTune(t *testing.T) {
+	dom, conn := buildTestQEMUDomain(testQEMUDisk)
+	defer func() {
+		dom.Undefine()
+		dom.Free()
+		if res, _ := conn.CloseConnection(); res != 0 {
+			t.Errorf("CloseConnection() == %d, expected 0", res)
+		}
+	}()
+
+	tune := BlockIoTune{
+		TotalBytesSecSet: true,
+		TotalBytesSec:    10 << 20,
+		TotalIopsSecSet:  true,
+		TotalIopsSec:     400,
+	}
+	if err := dom.SetBlockIoTune("vda", &tune, VIR_DOMAIN_AFFECT_CONFIG); err != nil {
+		t.Fatal(err)
+	}
+	got, err := dom.GetBlockIoTune("vda", VIR_DOMAIN_AFFECT_CONFIG)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.TotalBytesSecSet || got.TotalBytesSec != 10<<20 || got.TotalIopsSec != 400 {
+		t.Errorf("GetBlockIoTune() == %+v", got)
+	}
+	if got.ReadBytesSec != 0 {
+		t.Errorf("ReadBytesSec == %d, expected 0", got.ReadBytesSec)
+	}
+}
+
+func TestBlkioParametersEncoding(t *testing.T) {
+	blkio := DomainBlkioParameters{
+		WeightSet:      true,
+		Weight:         500,
+		DeviceWeights:  []BlkioDevice{{"/dev/sda", 200}, {"/dev/sdb", 0}},
+		DeviceReadIops: []BlkioDevice{{"/dev/sda", 1000}},
+	}
+	params := blkio.typedParams()
+	expected := VirTypedParameters{
+		{VIR_DOMAIN_BLKIO_WEIGHT, uint(500)},
+		{VIR_DOMAIN_BLKIO_DEVICE_WEIGHT, "/dev/sda,200,/dev/sdb,0"},
+		{VIR_DOMAIN_BLKIO_DEVICE_READ_IOPS, "/dev/sda,1000"},
+	}
+	if !reflect.DeepEqual(params, expected) {
+		t.Fatalf("encoded %v, expected %v", params, expected)
+	}
+
+	decodedParams, err := roundTripTypedParams(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded DomainBlkioParameters
+	if err := decoded.loadFromParams(decodedParams); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, blkio) {
+		t.Errorf("decoded %+v, expected %+v", decoded, blkio)
+	}
+
+	for _, malformed := range []string{"/dev/sda", "/dev/sda,heavy"} {
+		err := decoded.loadFromParams(VirTypedParameters{{VIR_DOMAIN_BLKIO_DEVICE_WEIGHT, malformed}})
+		if err == nil {
+			t.Errorf("expected error for %q", malformed)
+		}
+	}
+}
+
+func TestSchedulerParametersEncoding(t *testing.T) {
+	sched := DomainSchedulerParameters{
+		CpuSharesSet:  true,
+		CpuShares:     2048,
+		VcpuQuotaSet:  true,
+		VcpuQuota:     -1,
+		VcpuPeriod:    100000,
+		EmulatorQuota: 5000,
+	}
+	params := encodeTypedParams(sched.fields())
+	expected := VirTypedParameters{
+		{VIR_DOMAIN_SCHEDULER_CPU_SHARES, uint64(2048)},
+		{VIR_DOMAIN_SCHEDULER_VCPU_QUOTA, int64(-1)},
+	}
+	if !reflect.DeepEqual(params, expected) {
+		t.Errorf("encoded %v, expected %v", params, expected)
+	}
+}
+
+func TestDomainTuningParameters(t *testing.T) {
+	dom, conn := buildTestQEMUDomain("")
+	defer func() {
+		dom.Undefine()
+		dom.Free()
+		if res, _ := conn.CloseConnection(); res != 0 {
+			t.Errorf("CloseConnection() == %d, expected 0", res)
+		}
+	}()
+
+	if err := dom.SetSchedulerParameters(&DomainSchedulerParameters{CpuSharesSet: true, CpuShares: 2048}, VIR_DOMAIN_AFFECT_CONFIG); err != nil {
+		t.Fatal(err)
+	}
+	sched, err := dom.GetSchedulerParameters(VIR_DOMAIN_AFFECT_CONFIG)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sched.Type != "posix" || !sched.CpuSharesSet || sched.CpuShares != 2048 {
+		t.Errorf("GetSchedulerParameters() == %+v", sched)
+	}
+
+	if err := dom.SetMemoryParameters(&DomainMemoryParameters{SoftLimitSet: true, SoftLimit: 65536}, VIR_DOMAIN_AFFECT_CONFIG); err != nil {
+		t.Fatal(err)
+	}
+	mem, err := dom.GetMemoryParameters(VIR_DOMAIN_AFFECT_CONFIG)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mem.SoftLimit != 65536 || mem.HardLimit != VIR_DOMAIN_MEMORY_PARAM_UNLIMITED {
+		t.Errorf("GetMemoryParameters() == %+v", mem)
+	}
+
+	if err := dom.SetBlkioParameters(&DomainBlkioParameters{WeightSet: true, Weight: 300}, VIR_DOMAIN_AFFECT_CONFIG); err != nil {
+		t.Fatal(err)
+	}
+	blkio, err := dom.GetBlkioParameters(VIR_DOMAIN_AFFECT_CONFIG)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blkio.Weight != 300 {
+		t.Errorf("GetBlkioParameters().Weight == %d, expected 300", blkio.Weight)
+	}
+
+	numa := DomainNumaParameters{Nodeset: "0", ModeSet: true, Mode: VIR_DOMAIN_NUMATUNE_MEM_PREFERRED}
+	if err := dom.SetNumaParameters(&numa, VIR_DOMAIN_AFFECT_CONFIG); err != nil {
+		t.Fatal(err)
+	}
+	got, err := dom.GetNumaParameters(VIR_DOMAIN_AFFECT_CONFIG)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *got != numa {
+		t.Errorf("GetNumaParameters() == %+v, expected %+v", got, numa)
+	}
+}
+
+func TestInterfaceBandwidthEncoding(t *testing.T) {
+	bandwidth := InterfaceBandwidth{
+		InboundAverageSet:  true,
+		InboundAverage:     1000,
+		InboundBurstSet:    true,
+		InboundBurst:       2048,
+		InboundFloorSet:    true,
+		InboundFloor:       200,
+		OutboundAverageSet: true,
+		OutboundAverage:    0,
+		OutboundPeak:       5000,
+	}
+	params := encodeTypedParams(bandwidth.fields())
+	expected := VirTypedParameters{
+		{VIR_DOMAIN_BANDWIDTH_IN_AVERAGE, uint(1000)},
+		{VIR_DOMAIN_BANDWIDTH_IN_BURST, uint(2048)},
+		{VIR_DOMAIN_BANDWIDTH_IN_FLOOR, uint(200)},
+		{VIR_DOMAIN_BANDWIDTH_OUT_AVERAGE, uint(0)},
+	}
+	if !reflect.DeepEqual(params, expected) {
+		t.Fatalf("encoded %v, expected %v", params, expected)
+	}
+
+	decodedParams, err := roundTripTypedParams(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded InterfaceBandwidth
+	decodeTypedParams(decoded.fields(), decodedParams)
+	bandwidth.OutboundPeak = 0
+	if decoded != bandwidth {
+		t.Errorf("decoded %+v, expected %+v", decoded, bandwidth)
+	}
+}
@@ -410,9 +410,7 @@ func (c *VirConnection) DomainEventRegister(dom VirDomain,
 		callbackPtr = unsafe.Pointer(C.domainEventGraphicsCallback_cgo)
 	case VIR_DOMAIN_EVENT_ID_IO_ERROR_REASON:
 		callbackPtr = unsafe.Pointer(C.domainEventIOErrorReasonCallback_cgo)
-	case VIR_DOMAIN_EVENT_ID_BLOCK_JOB:
-		// TODO Post 1.2.4, uncomment later
-		// case VIR_DOMAIN_EVENT_ID_BLOCK_JOB_2:
+	case VIR_DOMAIN_EVENT_ID_BLOCK_JOB, VIR_DOMAIN_EVENT_ID_BLOCK_JOB_2:
 		callbackPtr = unsafe.Pointer(C.domainEventBlockJobCallback_cgo)
 	case VIR_DOMAIN_EVENT_ID_DISK_CHANGE:
 		callbackPtr = unsafe.Pointer(C.domainEventDiskChangeCallback_cgo)
@@ -623,9 +621,8 @@ func (e DomainBlockJobEvent) String() string {
 		_type = "block copy (job exists as long as mirroring is active)"
 	case VIR_DOMAIN_BLOCK_JOB_TYPE_COMMIT:
 		_type = "block commit (job ends on completion)"
-	// TODO Post 1.2.4, enable later
-	// case VIR_DOMAIN_BLOCK_JOB_TYPE_ACTIVE_COMMIT:
-	//	_type = "active block commit (job exists as long as sync is active)"
+	case VIR_DOMAIN_BLOCK_JOB_TYPE_ACTIVE_COMMIT:
+		_type = "active block commit (job exists as long as sync is active)"
 	default:
 		_type = "unknown"
 	}
@@ -2,6 +2,7 @@
 #define GO_LIBVIRT_H
 void closeCallback_cgo(virConnectPtr conn, int reason, void *opaque);
 int virConnectRegisterCloseCallback_cgo(virConnectPtr c, virConnectCloseFunc cb, long goCallbackId);
+int virStreamEventAddCallback_cgo(virStreamPtr st, int events, long goCallbackId);
 
 typedef struct auth_cb_data {
     char* username;
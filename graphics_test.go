@@ -0,0 +1,41 @@
+package libvirt
+
+import (
+	"io"
+	"testing"
+)
+
+func buildTestQEMUGraphicsDomain(t *testing.T, conn VirConnection) VirDomain {
+	return buildTestQEMURunningDomain(t, conn, "test-open-graphics", "<graphics type='vnc' autoport='yes'/>")
+}
+
+func TestDomainOpenGraphics(t *testing.T) {
+	conn := buildTestQEMUConnection()
+	defer func() {
+		if res, _ := conn.CloseConnection(); res != 0 {
+			t.Errorf("CloseConnection() == %d, expected 0", res)
+		}
+	}()
+	dom := buildTestQEMUGraphicsDomain(t, conn)
+	defer func() {
+		dom.Destroy()
+		dom.Free()
+	}()
+
+	client, err := dom.OpenGraphics(0, VIR_DOMAIN_OPEN_GRAPHICS_SKIPAUTH)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	banner := make([]byte, 12)
+	if _, err := io.ReadFull(client, banner); err != nil {
+		t.Fatal(err)
+	}
+	if string(banner[:4]) != "RFB " {
+		t.Errorf("unexpected RFB banner %q", banner)
+	}
+
+	if _, err := dom.OpenGraphics(1, 0); err == nil {
+		t.Error("expected error opening a missing graphics device")
+	}
+}
@@ -0,0 +1,176 @@
+package libvirt
+
+import (
+	"fmt"
+	"time"
+)
+
+// GuestInfo is the information about a guest reported by its guest agent,
+// as returned by GetGuestInfo. Only the parts asked for, and supported by
+// the agent, are filled in; OS and Timezone are nil otherwise.
+type GuestInfo struct {
+	Users       []GuestUser
+	OS          *GuestOSInfo
+	Timezone    *GuestTimezone
+	Hostname    string
+	FileSystems []GuestFileSystem
+	Disks       []GuestDisk
+}
+
+// GuestUser is a user logged in to the guest.
+type GuestUser struct {
+	Name      string
+	Domain    string // Windows domain of the user, if any
+	LoginTime time.Time
+}
+
+// GuestOSInfo identifies the operating system of the guest, mostly from
+// its os-release file.
+type GuestOSInfo struct {
+	ID            string // e.g. "fedora"
+	Name          string // e.g. "Fedora"
+	PrettyName    string // e.g. "Fedora 31 (Server Edition)"
+	Version       string // e.g. "31 (Server Edition)"
+	VersionID     string // e.g. "31"
+	KernelRelease string // e.g. "5.3.7-301.fc31.x86_64"
+	KernelVersion string
+	Machine       string // e.g. "x86_64"
+	Variant       string
+	VariantID     string
+}
+
+// GuestTimezone is the time zone of the guest.
+type GuestTimezone struct {
+	Name   string // May be empty
+	Offset int    // Offset to UTC in seconds
+}
+
+// GuestFileSystem is a file system mounted in the guest.
+type GuestFileSystem struct {
+	Mountpoint    string
+	Name          string
+	FSType        string
+	TotalBytesSet bool
+	TotalBytes    uint64
+	UsedBytesSet  bool
+	UsedBytes     uint64
+	Disks         []GuestFSDisk
+}
+
+// GuestFSDisk is a disk holding a guest file system.
+type GuestFSDisk struct {
+	Alias  string // Alias of the disk in the domain XML, e.g. "vda"
+	Serial string
+	Device string // Device node in the guest, e.g. "/dev/vda1"
+}
+
+// GuestDisk is a block device of the guest.
+type GuestDisk struct {
+	Name         string // Device node in the guest, e.g. "/dev/vda1"
+	Partition    bool
+	Dependencies []string // Devices this one is built on, e.g. a partition's disk
+	Alias        string   // Alias of the disk in the domain XML
+	GuestAlias   string
+	Serial       string
+}
+
+// guestInfoCount returns the number of entries of the list with the given
+// key prefix, such as "user" or "fs.0.disk".
+func guestInfoCount(params VirTypedParameters, prefix string) int {
+	var count uint
+	decodeTypedParams([]typedParamField{{prefix + ".count", nil, &count}}, params)
+	return int(count)
+}
+
+// loadFromParams decodes the flat "user.0.name" style parameters returned
+// by virDomainGetGuestInfo.
+func (info *GuestInfo) loadFromParams(params VirTypedParameters) {
+	for i := 0; i < guestInfoCount(params, "user"); i++ {
+		var user GuestUser
+		var loginTime uint64
+		prefix := fmt.Sprintf("user.%d.", i)
+		decodeTypedParams([]typedParamField{
+			{prefix + "name", nil, &user.Name},
+			{prefix + "domain", nil, &user.Domain},
+			{prefix + "login-time", nil, &loginTime},
+		}, params)
+		if loginTime != 0 {
+			// Milliseconds since the epoch
+			user.LoginTime = time.Unix(int64(loginTime/1000), int64(loginTime%1000)*int64(time.Millisecond))
+		}
+		info.Users = append(info.Users, user)
+	}
+
+	var osInfo GuestOSInfo
+	var osSet bool
+	decodeTypedParams([]typedParamField{
+		{"os.id", &osSet, &osInfo.ID},
+		{"os.name", &osSet, &osInfo.Name},
+		{"os.pretty-name", &osSet, &osInfo.PrettyName},
+		{"os.version", &osSet, &osInfo.Version},
+		{"os.version-id", &osSet, &osInfo.VersionID},
+		{"os.kernel-release", &osSet, &osInfo.KernelRelease},
+		{"os.kernel-version", &osSet, &osInfo.KernelVersion},
+		{"os.machine", &osSet, &osInfo.Machine},
+		{"os.variant", &osSet, &osInfo.Variant},
+		{"os.variant-id", &osSet, &osInfo.VariantID},
+	}, params)
+	if osSet {
+		info.OS = &osInfo
+	}
+
+	var timezone GuestTimezone
+	var timezoneSet bool
+	decodeTypedParams([]typedParamField{
+		{"timezone.name", nil, &timezone.Name},
+		{"timezone.offset", &timezoneSet, &timezone.Offset},
+	}, params)
+	if timezoneSet {
+		info.Timezone = &timezone
+	}
+
+	decodeTypedParams([]typedParamField{{"hostname", nil, &info.Hostname}}, params)
+
+	for i := 0; i < guestInfoCount(params, "fs"); i++ {
+		var fs GuestFileSystem
+		prefix := fmt.Sprintf("fs.%d.", i)
+		decodeTypedParams([]typedParamField{
+			{prefix + "mountpoint", nil, &fs.Mountpoint},
+			{prefix + "name", nil, &fs.Name},
+			{prefix + "fstype", nil, &fs.FSType},
+			{prefix + "total-bytes", &fs.TotalBytesSet, &fs.TotalBytes},
+			{prefix + "used-bytes", &fs.UsedBytesSet, &fs.UsedBytes},
+		}, params)
+		for k := 0; k < guestInfoCount(params, prefix+"disk"); k++ {
+			var disk GuestFSDisk
+			diskPrefix := fmt.Sprintf("%sdisk.%d.", prefix, k)
+			decodeTypedParams([]typedParamField{
+				{diskPrefix + "alias", nil, &disk.Alias},
+				{diskPrefix + "serial", nil, &disk.Serial},
+				{diskPrefix + "device", nil, &disk.Device},
+			}, params)
+			fs.Disks = append(fs.Disks, disk)
+		}
+		info.FileSystems = append(info.FileSystems, fs)
+	}
+
+	for i := 0; i < guestInfoCount(params, "disk"); i++ {
+		var disk GuestDisk
+		prefix := fmt.Sprintf("disk.%d.", i)
+		decodeTypedParams([]typedParamField{
+			{prefix + "name", nil, &disk.Name},
+			{prefix + "partition", nil, &disk.Partition},
+			{prefix + "alias", nil, &disk.Alias},
+			{prefix + "guest_alias", nil, &disk.GuestAlias},
+			{prefix + "serial", nil, &disk.Serial},
+		}, params)
+		for k := 0; k < guestInfoCount(params, prefix+"dependency"); k++ {
+			var dependency string
+			decodeTypedParams([]typedParamField{
+				{fmt.Sprintf("%sdependency.%d.name", prefix, k), nil, &dependency},
+			}, params)
+			disk.Dependencies = append(disk.Dependencies, dependency)
+		}
+		info.Disks = append(info.Disks, disk)
+	}
+}
@@ -0,0 +1,75 @@
+package libvirt
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGuestInfoDecoding(t *testing.T) {
+	params := VirTypedParameters{
+		{"user.count", uint32(2)},
+		{"user.0.name", "root"},
+		{"user.0.login-time", uint64(1572000000500)},
+		{"user.1.name", "alice"},
+		{"user.1.domain", "CORP"},
+		{"os.id", "fedora"},
+		{"os.version-id", "31"},
+		{"os.kernel-release", "5.3.7-301.fc31.x86_64"},
+		{"timezone.offset", int(3600)},
+		{"hostname", "web1"},
+		{"fs.count", uint32(1)},
+		{"fs.0.mountpoint", "/"},
+		{"fs.0.name", "vda1"},
+		{"fs.0.fstype", "xfs"},
+		{"fs.0.total-bytes", uint64(10 << 30)},
+		{"fs.0.disk.count", uint32(1)},
+		{"fs.0.disk.0.alias", "vda"},
+		{"fs.0.disk.0.device", "/dev/vda1"},
+		{"disk.count", uint32(2)},
+		{"disk.0.name", "/dev/vda"},
+		{"disk.0.alias", "vda"},
+		{"disk.1.name", "/dev/vda1"},
+		{"disk.1.partition", true},
+		{"disk.1.dependency.count", uint32(1)},
+		{"disk.1.dependency.0.name", "/dev/vda"},
+		{"unknown.key", "ignored"},
+	}
+	var info GuestInfo
+	info.loadFromParams(params)
+
+	expected := GuestInfo{
+		Users: []GuestUser{
+			{Name: "root", LoginTime: time.Unix(1572000000, 500*int64(time.Millisecond))},
+			{Name: "alice", Domain: "CORP"},
+		},
+		OS: &GuestOSInfo{
+			ID:            "fedora",
+			VersionID:     "31",
+			KernelRelease: "5.3.7-301.fc31.x86_64",
+		},
+		Timezone: &GuestTimezone{Offset: 3600},
+		Hostname: "web1",
+		FileSystems: []GuestFileSystem{{
+			Mountpoint:    "/",
+			Name:          "vda1",
+			FSType:        "xfs",
+			TotalBytesSet: true,
+			TotalBytes:    10 << 30,
+			Disks:         []GuestFSDisk{{Alias: "vda", Device: "/dev/vda1"}},
+		}},
+		Disks: []GuestDisk{
+			{Name: "/dev/vda", Alias: "vda"},
+			{Name: "/dev/vda1", Partition: true, Dependencies: []string{"/dev/vda"}},
+		},
+	}
+	if !reflect.DeepEqual(info, expected) {
+		t.Errorf("decoded %+v, expected %+v", info, expected)
+	}
+
+	var empty GuestInfo
+	empty.loadFromParams(VirTypedParameters{{"hostname", "web1"}})
+	if empty.OS != nil || empty.Timezone != nil || empty.Users != nil {
+		t.Errorf("decoded %+v from a hostname only", empty)
+	}
+}
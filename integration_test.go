@@ -3,6 +3,7 @@
 package libvirt
 
 import (
+	"io"
 	"io/ioutil"
 	"os"
 	"strings"
@@ -196,6 +197,81 @@ func TestIntegrationNWFilterGetName(t *testing.T) {
 	}
 }
 
+func TestIntegrationNWFilterBuilderDefine(t *testing.T) {
+	conn, err := NewVirConnection("lxc:///")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer func() {
+		if res, _ := conn.CloseConnection(); res != 0 {
+			t.Errorf("CloseConnection() == %d, expected 0", res)
+		}
+	}()
+	def, err := NewNWFilterBuilder("libvirt-go-allow-ssh", NWFILTER_CHAIN_ROOT).
+		AllowTCPIn(22).
+		AllowEstablished().
+		DropAll().
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := def.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	filter, err := conn.NWFilterDefineXML(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		filter.Undefine()
+		filter.Free()
+	}()
+	desc, err := filter.GetXMLDesc(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got NWFilterXML
+	if err := got.Unmarshal(desc); err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != def.Name || len(got.Entries) != len(def.Entries) {
+		t.Errorf("defined filter does not match:\n%s", desc)
+	}
+}
+
+func TestIntegrationNWFilterEvaluateBuiltin(t *testing.T) {
+	conn, err := NewVirConnection("lxc:///")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer func() {
+		if res, _ := conn.CloseConnection(); res != 0 {
+			t.Errorf("CloseConnection() == %d, expected 0", res)
+		}
+	}()
+	resolve := conn.NWFilterResolver()
+	root, err := resolve("clean-traffic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	vars := map[string][]string{"MAC": {"52:54:00:aa:bb:cc"}, "IP": {"10.0.0.5"}}
+	eval, err := NewNWFilterEvaluator(root, resolve, vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verdict, err := eval.Evaluate(NWFilterPacket{Direction: NWFILTER_DIRECTION_OUT,
+		SrcMAC: "52:54:00:aa:bb:cc", SrcIP: "10.0.0.9", DstIP: "10.0.0.1", Protocol: "udp", DstPort: 53})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verdict.Action != NWFILTER_ACTION_DROP {
+		t.Errorf("spoofed packet got %s by %s, expected drop", verdict.Action, verdict.Filter)
+	}
+}
+
 func TestIntegrationNWFilterGetUUID(t *testing.T) {
 	conn, err := NewVirConnection("lxc:///")
 	if err != nil {
@@ -811,14 +887,7 @@ func TestIntegrationGetDomainCPUStats(t *testing.T) {
 // 		}
 // 	}()
 // 	iface := "either mac or path to interface"
-// 	nparams := int(0)
-// 	if _, err := dom.GetInterfaceParameters(iface, nil, &nparams, 0); err != nil {
-// 		t.Error(err)
-// 		return
-// 	}
-
-// 	var params VirTypedParameters
-// 	if _, err := dom.GetInterfaceParameters(iface, &params, &nparams, 0); err != nil {
+// 	if _, err := dom.GetInterfaceParameters(iface, 0); err != nil {
 // 		t.Error(err)
 // 		return
 // 	}
@@ -1109,3 +1178,69 @@ func TestStorageVolUploadDownload(t *testing.T) {
 		t.Fatal("Should have got one result, got", len(ms))
 	}
 }*/
+
+func TestIntegrationDomainOpenConsole(t *testing.T) {
+	EventRegisterDefaultImpl()
+	go func() {
+		for {
+			EventRunDefaultImpl()
+		}
+	}()
+
+	conn, err := NewVirConnection("lxc:///")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if res, _ := conn.CloseConnection(); res != 0 {
+			t.Errorf("CloseConnection() == %d, expected 0", res)
+		}
+	}()
+	dom, err := defineTestLxcDomain(conn, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		dom.Undefine()
+		dom.Free()
+	}()
+	if err := dom.Create(); err != nil {
+		t.Fatal(err)
+	}
+	defer dom.Destroy()
+
+	console, err := dom.OpenConsole("", VIR_DOMAIN_CONSOLE_FORCE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := console.Write([]byte("echo con$((6*7))sole\n")); err != nil {
+		t.Fatal(err)
+	}
+	var output []byte
+	buf := make([]byte, 1024)
+	for !strings.Contains(string(output), "con42sole") {
+		n, err := console.Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		output = append(output, buf[:n]...)
+	}
+
+	done := make(chan error)
+	go func() {
+		_, err := console.Read(buf)
+		done <- err
+	}()
+	time.Sleep(100 * time.Millisecond)
+	if err := console.Close(); err != nil {
+		t.Error(err)
+	}
+	select {
+	case err := <-done:
+		if err != io.ErrClosedPipe {
+			t.Errorf("Read() after Close() == %v, expected %v", err, io.ErrClosedPipe)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close() did not unblock Read()")
+	}
+}
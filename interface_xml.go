@@ -0,0 +1,107 @@
+package libvirt
+
+import (
+	"encoding/xml"
+)
+
+// InterfaceXML is the typed form of the <interface> document accepted by
+// InterfaceDefineXML and returned by VirInterface.GetXMLDesc. Bridges and
+// bonds list their member interfaces with the same type.
+type InterfaceXML struct {
+	XMLName   xml.Name            `xml:"interface"`
+	Type      string              `xml:"type,attr,omitempty"`
+	Name      string              `xml:"name,attr,omitempty"`
+	Start     *InterfaceStart     `xml:"start"`
+	MAC       *InterfaceMAC       `xml:"mac"`
+	MTU       *InterfaceMTU       `xml:"mtu"`
+	Link      *InterfaceLink      `xml:"link"`
+	Protocols []InterfaceProtocol `xml:"protocol"`
+	Bridge    *InterfaceBridge    `xml:"bridge"`
+	Bond      *InterfaceBond      `xml:"bond"`
+	VLAN      *InterfaceVLAN      `xml:"vlan"`
+}
+
+// InterfaceStart sets when the interface is brought up: "onboot", "none"
+// or "hotplug".
+type InterfaceStart struct {
+	Mode string `xml:"mode,attr"`
+}
+
+type InterfaceMAC struct {
+	Address string `xml:"address,attr"`
+}
+
+type InterfaceMTU struct {
+	Size uint `xml:"size,attr"`
+}
+
+type InterfaceLink struct {
+	Speed uint   `xml:"speed,attr,omitempty"`
+	State string `xml:"state,attr,omitempty"`
+}
+
+// InterfaceProtocol configures addressing for one family, "ipv4" or
+// "ipv6".
+type InterfaceProtocol struct {
+	Family   string           `xml:"family,attr"`
+	AutoConf *struct{}        `xml:"autoconf"`
+	DHCP     *InterfaceDHCP   `xml:"dhcp"`
+	IPs      []InterfaceIP    `xml:"ip"`
+	Routes   []InterfaceRoute `xml:"route"`
+}
+
+type InterfaceDHCP struct {
+	PeerDNS string `xml:"peerdns,attr,omitempty"`
+}
+
+type InterfaceIP struct {
+	Address string `xml:"address,attr"`
+	Prefix  uint   `xml:"prefix,attr,omitempty"`
+}
+
+type InterfaceRoute struct {
+	Gateway string `xml:"gateway,attr"`
+}
+
+type InterfaceBridge struct {
+	STP        string         `xml:"stp,attr,omitempty"`
+	Delay      string         `xml:"delay,attr,omitempty"`
+	Interfaces []InterfaceXML `xml:"interface"`
+}
+
+type InterfaceBond struct {
+	Mode       string               `xml:"mode,attr,omitempty"`
+	MIIMon     *InterfaceBondMIIMon `xml:"miimon"`
+	ARPMon     *InterfaceBondARPMon `xml:"arpmon"`
+	Interfaces []InterfaceXML       `xml:"interface"`
+}
+
+type InterfaceBondMIIMon struct {
+	Freq      uint   `xml:"freq,attr"`
+	UpDelay   uint   `xml:"updelay,attr,omitempty"`
+	DownDelay uint   `xml:"downdelay,attr,omitempty"`
+	Carrier   string `xml:"carrier,attr,omitempty"`
+}
+
+type InterfaceBondARPMon struct {
+	Interval uint   `xml:"interval,attr"`
+	Target   string `xml:"target,attr"`
+	Validate string `xml:"validate,attr,omitempty"`
+}
+
+type InterfaceVLAN struct {
+	Tag       uint          `xml:"tag,attr"`
+	Interface *InterfaceXML `xml:"interface"`
+}
+
+func (i *InterfaceXML) Marshal() (string, error) {
+	doc, err := xml.MarshalIndent(i, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(doc), nil
+}
+
+func (i *InterfaceXML) Unmarshal(doc string) error {
+	return xml.Unmarshal([]byte(doc), i)
+}
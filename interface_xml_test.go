@@ -0,0 +1,134 @@
+package libvirt
+
+import (
+	"reflect"
+	"testing"
+)
+
+var testInterfaceXMLs = []string{
+	`<interface type="ethernet" name="eth0">
+  <start mode="onboot"></start>
+  <mac address="aa:bb:cc:dd:ee:ff"></mac>
+  <mtu size="1492"></mtu>
+  <protocol family="ipv4">
+    <dhcp peerdns="no"></dhcp>
+  </protocol>
+  <protocol family="ipv6">
+    <autoconf></autoconf>
+    <ip address="3ffe:ffff:0:5::1" prefix="128"></ip>
+    <route gateway="3ffe:ffff:0:5::ffff"></route>
+  </protocol>
+</interface>`,
+	`<interface type="bridge" name="br0">
+  <start mode="onboot"></start>
+  <mtu size="1500"></mtu>
+  <protocol family="ipv4">
+    <ip address="192.168.0.5" prefix="24"></ip>
+    <route gateway="192.168.0.1"></route>
+  </protocol>
+  <bridge stp="off" delay="0.01">
+    <interface type="ethernet" name="eth0">
+      <mac address="ab:bb:cc:dd:ee:ff"></mac>
+    </interface>
+    <interface type="ethernet" name="eth1"></interface>
+  </bridge>
+</interface>`,
+	`<interface type="bond" name="bond0">
+  <start mode="none"></start>
+  <protocol family="ipv4">
+    <dhcp></dhcp>
+  </protocol>
+  <bond mode="active-backup">
+    <miimon freq="100" updelay="10" carrier="ioctl"></miimon>
+    <interface type="ethernet" name="eth0">
+      <mac address="ab:bb:cc:dd:ee:ff"></mac>
+    </interface>
+    <interface type="ethernet" name="eth1">
+      <mac address="ab:bb:cc:dd:ee:fe"></mac>
+    </interface>
+  </bond>
+</interface>`,
+	`<interface type="vlan" name="eth0.42">
+  <start mode="onboot"></start>
+  <protocol family="ipv4">
+    <dhcp peerdns="no"></dhcp>
+  </protocol>
+  <vlan tag="42">
+    <interface name="eth0"></interface>
+  </vlan>
+</interface>`,
+}
+
+func TestInterfaceXMLRoundTrip(t *testing.T) {
+	for _, doc := range testInterfaceXMLs {
+		var iface InterfaceXML
+		if err := iface.Unmarshal(doc); err != nil {
+			t.Fatal(err)
+		}
+		out, err := iface.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out != doc {
+			t.Errorf("round trip mismatch:\n%s\n%s", doc, out)
+		}
+	}
+}
+
+func TestInterfaceXMLMembers(t *testing.T) {
+	var bond, vlan InterfaceXML
+	if err := bond.Unmarshal(testInterfaceXMLs[2]); err != nil {
+		t.Fatal(err)
+	}
+	if bond.Bond.MIIMon.Freq != 100 || len(bond.Bond.Interfaces) != 2 || bond.Bond.Interfaces[1].MAC.Address != "ab:bb:cc:dd:ee:fe" {
+		t.Errorf("bond not parsed: %+v", bond.Bond)
+	}
+	if err := vlan.Unmarshal(testInterfaceXMLs[3]); err != nil {
+		t.Fatal(err)
+	}
+	if vlan.VLAN.Tag != 42 || vlan.VLAN.Interface.Name != "eth0" {
+		t.Errorf("vlan not parsed: %+v", vlan.VLAN)
+	}
+}
+
+func TestInterfaceXMLDefine(t *testing.T) {
+	conn := buildTestConnection()
+	defer func() {
+		if res, _ := conn.CloseConnection(); res != 0 {
+			t.Errorf("CloseConnection() == %d, expected 0", res)
+		}
+	}()
+	def := InterfaceXML{
+		Type:  "ethernet",
+		Name:  "ethTyped0",
+		Start: &InterfaceStart{Mode: "onboot"},
+		MAC:   &InterfaceMAC{Address: generateRandomMac()},
+		Protocols: []InterfaceProtocol{{
+			Family: "ipv4",
+			IPs:    []InterfaceIP{{Address: "192.168.0.5", Prefix: 24}},
+		}},
+	}
+	doc, err := def.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	iface, err := conn.InterfaceDefineXML(doc, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		iface.Undefine()
+		iface.Free()
+	}()
+	desc, err := iface.GetXMLDesc(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got InterfaceXML
+	if err := got.Unmarshal(desc); err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != def.Name || got.MAC.Address != def.MAC.Address || !reflect.DeepEqual(got.Protocols, def.Protocols) {
+		t.Errorf("defined interface does not match:\n%s", desc)
+	}
+}
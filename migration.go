@@ -0,0 +1,171 @@
+package libvirt
+
+/*
+#cgo LDFLAGS: -lvirt
+#include <libvirt/libvirt.h>
+#include <libvirt/virterror.h>
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"unsafe"
+)
+
+// MigrationParams holds the typed parameters accepted by Migrate3 and
+// MigrateToURI3. String and list fields are only sent when non-empty;
+// numeric fields are only sent when the matching Set field is true, since
+// zero is a meaningful value for most of them.
+type MigrationParams struct {
+	URI                       string // Destination URI for the migration data stream
+	DestName                  string // Name of the domain on the destination
+	DestXML                   string // Domain XML used to start the domain on the destination
+	PersistXML                string // Domain XML made persistent on the destination
+	BandwidthSet              bool
+	Bandwidth                 uint64   // Maximum bandwidth in MiB/s
+	GraphicsURI               string   // URI clients should reconnect their graphics session to
+	ListenAddress             string   // Address the destination listens on for incoming migration
+	MigrateDisks              []string // Target devices of the disks to copy with NON_SHARED_DISK
+	DisksPortSet              bool
+	DisksPort                 int      // Port used by the destination NBD server for disk copy
+	Compression               []string // Compression methods, e.g. "xbzrle" or "mt"
+	CompressionMTLevelSet     bool
+	CompressionMTLevel        int // Level for the "mt" method
+	CompressionMTThreadsSet   bool
+	CompressionMTThreads      int // Compression threads for the "mt" method
+	CompressionMTDThreadsSet  bool
+	CompressionMTDThreads     int // Decompression threads for the "mt" method
+	CompressionXBZRLECacheSet bool
+	CompressionXBZRLECache    uint64 // Page cache size in bytes for the "xbzrle" method
+	AutoConvergeInitialSet    bool
+	AutoConvergeInitial       int // Initial CPU throttling percentage with AUTO_CONVERGE
+	AutoConvergeIncrementSet  bool
+	AutoConvergeIncrement     int // Throttling increment percentage with AUTO_CONVERGE
+	ParallelConnectionsSet    bool
+	ParallelConnections       int // Number of connections used with PARALLEL
+}
+
+func (p *MigrationParams) typedParams() VirTypedParameters {
+	return encodeTypedParams([]typedParamField{
+		{VIR_MIGRATE_PARAM_URI, nil, &p.URI},
+		{VIR_MIGRATE_PARAM_DEST_NAME, nil, &p.DestName},
+		{VIR_MIGRATE_PARAM_DEST_XML, nil, &p.DestXML},
+		{VIR_MIGRATE_PARAM_PERSIST_XML, nil, &p.PersistXML},
+		{VIR_MIGRATE_PARAM_BANDWIDTH, &p.BandwidthSet, &p.Bandwidth},
+		{VIR_MIGRATE_PARAM_GRAPHICS_URI, nil, &p.GraphicsURI},
+		{VIR_MIGRATE_PARAM_LISTEN_ADDRESS, nil, &p.ListenAddress},
+		{VIR_MIGRATE_PARAM_MIGRATE_DISKS, nil, &p.MigrateDisks},
+		{VIR_MIGRATE_PARAM_DISKS_PORT, &p.DisksPortSet, &p.DisksPort},
+		{VIR_MIGRATE_PARAM_COMPRESSION, nil, &p.Compression},
+		{VIR_MIGRATE_PARAM_COMPRESSION_MT_LEVEL, &p.CompressionMTLevelSet, &p.CompressionMTLevel},
+		{VIR_MIGRATE_PARAM_COMPRESSION_MT_THREADS, &p.CompressionMTThreadsSet, &p.CompressionMTThreads},
+		{VIR_MIGRATE_PARAM_COMPRESSION_MT_DTHREADS, &p.CompressionMTDThreadsSet, &p.CompressionMTDThreads},
+		{VIR_MIGRATE_PARAM_COMPRESSION_XBZRLE_CACHE, &p.CompressionXBZRLECacheSet, &p.CompressionXBZRLECache},
+		{VIR_MIGRATE_PARAM_AUTO_CONVERGE_INITIAL, &p.AutoConvergeInitialSet, &p.AutoConvergeInitial},
+		{VIR_MIGRATE_PARAM_AUTO_CONVERGE_INCREMENT, &p.AutoConvergeIncrementSet, &p.AutoConvergeIncrement},
+		{VIR_MIGRATE_PARAM_PARALLEL_CONNECTIONS, &p.ParallelConnectionsSet, &p.ParallelConnections},
+	})
+}
+
+// Migrate moves the domain to the host behind dconn and returns the domain
+// object on the destination. dname, uri and bandwidth (MiB/s) are optional.
+func (d *VirDomain) Migrate(dconn *VirConnection, flags MigrationFlags, dname string, uri string, bandwidth uint64) (VirDomain, error) {
+	var cdname, curi *C.char
+	if dname != "" {
+		cdname = C.CString(dname)
+		defer C.free(unsafe.Pointer(cdname))
+	}
+	if uri != "" {
+		curi = C.CString(uri)
+		defer C.free(unsafe.Pointer(curi))
+	}
+	ptr := C.virDomainMigrate(d.ptr, dconn.ptr, C.ulong(flags), cdname, curi, C.ulong(bandwidth))
+	if ptr == nil {
+		return VirDomain{}, GetLastError()
+	}
+	return VirDomain{ptr: ptr}, nil
+}
+
+// Migrate3 is like Migrate but takes its options as typed parameters.
+func (d *VirDomain) Migrate3(dconn *VirConnection, params *MigrationParams, flags MigrationFlags) (VirDomain, error) {
+	typedParams := params.typedParams()
+	cParams, cnParams, err := typedParams.loadToCPtr()
+	if err != nil {
+		return VirDomain{}, err
+	}
+	defer C.virTypedParamsFree(cParams, cnParams)
+
+	ptr := C.virDomainMigrate3(d.ptr, dconn.ptr, cParams, C.uint(cnParams), C.uint(flags))
+	if ptr == nil {
+		return VirDomain{}, GetLastError()
+	}
+	return VirDomain{ptr: ptr}, nil
+}
+
+// MigrateToURI migrates the domain without a destination connection. duri
+// is a libvirt URI with VIR_MIGRATE_PEER2PEER, a hypervisor URI otherwise.
+func (d *VirDomain) MigrateToURI(duri string, flags MigrationFlags, dname string, bandwidth uint64) error {
+	cduri := C.CString(duri)
+	defer C.free(unsafe.Pointer(cduri))
+	var cdname *C.char
+	if dname != "" {
+		cdname = C.CString(dname)
+		defer C.free(unsafe.Pointer(cdname))
+	}
+	result := C.virDomainMigrateToURI(d.ptr, cduri, C.ulong(flags), cdname, C.ulong(bandwidth))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
+
+// MigrateToURI3 is like MigrateToURI but takes its options as typed
+// parameters. dconnuri is required with VIR_MIGRATE_PEER2PEER and must be
+// empty otherwise.
+func (d *VirDomain) MigrateToURI3(dconnuri string, params *MigrationParams, flags MigrationFlags) error {
+	var cdconnuri *C.char
+	if dconnuri != "" {
+		cdconnuri = C.CString(dconnuri)
+		defer C.free(unsafe.Pointer(cdconnuri))
+	}
+	typedParams := params.typedParams()
+	cParams, cnParams, err := typedParams.loadToCPtr()
+	if err != nil {
+		return err
+	}
+	defer C.virTypedParamsFree(cParams, cnParams)
+
+	result := C.virDomainMigrateToURI3(d.ptr, cdconnuri, cParams, C.uint(cnParams), C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
+
+// MigrateSetMaxDowntime sets the maximum time in milliseconds the domain
+// may be paused at the end of a live migration.
+func (d *VirDomain) MigrateSetMaxDowntime(downtime uint64, flags uint32) error {
+	result := C.virDomainMigrateSetMaxDowntime(d.ptr, C.ulonglong(downtime), C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
+
+// MigrateSetMaxSpeed sets the maximum migration bandwidth in MiB/s.
+func (d *VirDomain) MigrateSetMaxSpeed(bandwidth uint64, flags uint32) error {
+	result := C.virDomainMigrateSetMaxSpeed(d.ptr, C.ulong(bandwidth), C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
+
+func (d *VirDomain) MigrateGetMaxSpeed(flags uint32) (uint64, error) {
+	var bandwidth C.ulong
+	result := C.virDomainMigrateGetMaxSpeed(d.ptr, &bandwidth, C.uint(flags))
+	if result == -1 {
+		return 0, GetLastError()
+	}
+	return uint64(bandwidth), nil
+}
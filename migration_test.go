@@ -0,0 +1,85 @@
+package libvirt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMigrationParamsEncoding(t *testing.T) {
+	params := MigrationParams{
+		URI:                    "tcp://dst.example.com",
+		DestName:               "renamed",
+		BandwidthSet:           true,
+		Bandwidth:              0,
+		ListenAddress:          "0.0.0.0",
+		MigrateDisks:           []string{"vda", "vdb"},
+		Compression:            []string{"xbzrle", "mt"},
+		CompressionMTLevelSet:  true,
+		CompressionMTLevel:     9,
+		ParallelConnectionsSet: true,
+		ParallelConnections:    4,
+		AutoConvergeIncrement:  10,
+		CompressionXBZRLECache: 1 << 20,
+	}
+	decoded, err := roundTripTypedParams(params.typedParams())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := VirTypedParameters{
+		{VIR_MIGRATE_PARAM_URI, "tcp://dst.example.com"},
+		{VIR_MIGRATE_PARAM_DEST_NAME, "renamed"},
+		{VIR_MIGRATE_PARAM_BANDWIDTH, uint64(0)},
+		{VIR_MIGRATE_PARAM_LISTEN_ADDRESS, "0.0.0.0"},
+		{VIR_MIGRATE_PARAM_MIGRATE_DISKS, "vda"},
+		{VIR_MIGRATE_PARAM_MIGRATE_DISKS, "vdb"},
+		{VIR_MIGRATE_PARAM_COMPRESSION, "xbzrle"},
+		{VIR_MIGRATE_PARAM_COMPRESSION, "mt"},
+		{VIR_MIGRATE_PARAM_COMPRESSION_MT_LEVEL, 9},
+		{VIR_MIGRATE_PARAM_PARALLEL_CONNECTIONS, 4},
+	}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("decoded %v, expected %v", decoded, expected)
+	}
+}
+
+func TestMigrationParamsEncodingMismatch(t *testing.T) {
+	params := VirTypedParameters{{VIR_MIGRATE_PARAM_BANDWIDTH, int8(1)}}
+	if _, _, err := params.loadToCPtr(); err == nil {
+		t.Error("expected error for unsupported value type")
+	}
+}
+
+func TestDomainMigrateSetMaxSpeed(t *testing.T) {
+	dom, conn := buildTestQEMUDomain("")
+	defer func() {
+		dom.Destroy()
+		dom.Undefine()
+		dom.Free()
+		if res, _ := conn.CloseConnection(); res != 0 {
+			t.Errorf("CloseConnection() == %d, expected 0", res)
+		}
+	}()
+
+	if err := dom.Create(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := dom.MigrateSetMaxSpeed(64, 0); err != nil {
+		t.Error(err)
+		return
+	}
+	speed, err := dom.MigrateGetMaxSpeed(0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if speed != 64 {
+		t.Errorf("MigrateGetMaxSpeed() == %d, expected 64", speed)
+	}
+
+	if err := dom.MigrateSetMaxDowntime(50, 0); err != nil {
+		t.Error(err)
+		return
+	}
+}
@@ -1,4 +1,4 @@
-// +build libvirt.1.2.14
+// +build libvirt.1.2.14 libvirt.1.2.16 libvirt.1.3.3 libvirt.3.7.0 libvirt.3.9.0 libvirt.4.10.0 libvirt.5.7.0 libvirt.6.10.0
 
 package libvirt
 
@@ -0,0 +1,442 @@
+package libvirt
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// NetworkXML is the typed form of the <network> document accepted by
+// NetworkDefineXML / NetworkCreateXML and returned by VirNetwork.GetXMLDesc.
+type NetworkXML struct {
+	XMLName             xml.Name            `xml:"network"`
+	IPv6                string              `xml:"ipv6,attr,omitempty"`
+	TrustGuestRxFilters string              `xml:"trustGuestRxFilters,attr,omitempty"`
+	Name                string              `xml:"name"`
+	UUID                string              `xml:"uuid,omitempty"`
+	Title               string              `xml:"title,omitempty"`
+	Description         string              `xml:"description,omitempty"`
+	Forward             *NetworkForward     `xml:"forward"`
+	Bridge              *NetworkBridge      `xml:"bridge"`
+	MAC                 *NetworkMAC         `xml:"mac"`
+	Domain              *NetworkDomain      `xml:"domain"`
+	MTU                 *NetworkMTU         `xml:"mtu"`
+	DNS                 *NetworkDNS         `xml:"dns"`
+	VirtualPort         *NetworkVirtualPort `xml:"virtualport"`
+	Bandwidth           *NetworkBandwidth   `xml:"bandwidth"`
+	VLAN                *NetworkVLAN        `xml:"vlan"`
+	PortGroups          []NetworkPortGroup  `xml:"portgroup"`
+	IPs                 []NetworkIP         `xml:"ip"`
+	Routes              []NetworkRoute      `xml:"route"`
+}
+
+// Forward modes understood by <forward mode='...'/>.
+const (
+	NETWORK_FORWARD_NAT         = "nat"
+	NETWORK_FORWARD_ROUTE       = "route"
+	NETWORK_FORWARD_OPEN        = "open"
+	NETWORK_FORWARD_BRIDGE      = "bridge"
+	NETWORK_FORWARD_PRIVATE     = "private"
+	NETWORK_FORWARD_VEPA        = "vepa"
+	NETWORK_FORWARD_PASSTHROUGH = "passthrough"
+	NETWORK_FORWARD_HOSTDEV     = "hostdev"
+)
+
+type NetworkForward struct {
+	Mode       string                    `xml:"mode,attr,omitempty"`
+	Dev        string                    `xml:"dev,attr,omitempty"`
+	Managed    string                    `xml:"managed,attr,omitempty"`
+	NAT        *NetworkForwardNAT        `xml:"nat"`
+	PFs        []NetworkForwardPF        `xml:"pf"`
+	Interfaces []NetworkForwardInterface `xml:"interface"`
+}
+
+type NetworkForwardNAT struct {
+	IPv6      string                   `xml:"ipv6,attr,omitempty"`
+	Addresses []NetworkForwardNATRange `xml:"address"`
+	Ports     []NetworkForwardNATRange `xml:"port"`
+}
+
+type NetworkForwardNATRange struct {
+	Start string `xml:"start,attr"`
+	End   string `xml:"end,attr"`
+}
+
+type NetworkForwardPF struct {
+	Dev string `xml:"dev,attr"`
+}
+
+type NetworkForwardInterface struct {
+	Dev string `xml:"dev,attr"`
+}
+
+type NetworkBridge struct {
+	Name            string `xml:"name,attr,omitempty"`
+	STP             string `xml:"stp,attr,omitempty"`
+	Delay           string `xml:"delay,attr,omitempty"`
+	MACTableManager string `xml:"macTableManager,attr,omitempty"`
+}
+
+type NetworkMAC struct {
+	Address string `xml:"address,attr"`
+}
+
+type NetworkDomain struct {
+	Name      string `xml:"name,attr"`
+	LocalOnly string `xml:"localOnly,attr,omitempty"`
+}
+
+type NetworkMTU struct {
+	Size uint `xml:"size,attr"`
+}
+
+type NetworkDNS struct {
+	Enable            string                `xml:"enable,attr,omitempty"`
+	ForwardPlainNames string                `xml:"forwardPlainNames,attr,omitempty"`
+	Forwarders        []NetworkDNSForwarder `xml:"forwarder"`
+	TXTs              []NetworkDNSTXT       `xml:"txt"`
+	Hosts             []NetworkDNSHost      `xml:"host"`
+	SRVs              []NetworkDNSSRV       `xml:"srv"`
+}
+
+type NetworkDNSForwarder struct {
+	Domain string `xml:"domain,attr,omitempty"`
+	Addr   string `xml:"addr,attr,omitempty"`
+}
+
+type NetworkDNSTXT struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type NetworkDNSHost struct {
+	IP        string   `xml:"ip,attr"`
+	Hostnames []string `xml:"hostname"`
+}
+
+type NetworkDNSSRV struct {
+	Service  string `xml:"service,attr,omitempty"`
+	Protocol string `xml:"protocol,attr,omitempty"`
+	Domain   string `xml:"domain,attr,omitempty"`
+	Target   string `xml:"target,attr,omitempty"`
+	Port     uint   `xml:"port,attr,omitempty"`
+	Priority uint   `xml:"priority,attr,omitempty"`
+	Weight   uint   `xml:"weight,attr,omitempty"`
+}
+
+type NetworkIP struct {
+	Address  string       `xml:"address,attr,omitempty"`
+	Family   string       `xml:"family,attr,omitempty"`
+	Netmask  string       `xml:"netmask,attr,omitempty"`
+	Prefix   uint         `xml:"prefix,attr,omitempty"`
+	LocalPtr string       `xml:"localPtr,attr,omitempty"`
+	TFTP     *NetworkTFTP `xml:"tftp"`
+	DHCP     *NetworkDHCP `xml:"dhcp"`
+}
+
+type NetworkTFTP struct {
+	Root string `xml:"root,attr"`
+}
+
+type NetworkDHCP struct {
+	Ranges []NetworkDHCPRange `xml:"range"`
+	Hosts  []NetworkDHCPHost  `xml:"host"`
+	Bootp  *NetworkDHCPBootp  `xml:"bootp"`
+}
+
+type NetworkDHCPRange struct {
+	Start string `xml:"start,attr"`
+	End   string `xml:"end,attr"`
+}
+
+type NetworkDHCPHost struct {
+	ID   string `xml:"id,attr,omitempty"`
+	MAC  string `xml:"mac,attr,omitempty"`
+	Name string `xml:"name,attr,omitempty"`
+	IP   string `xml:"ip,attr,omitempty"`
+}
+
+type NetworkDHCPBootp struct {
+	File   string `xml:"file,attr"`
+	Server string `xml:"server,attr,omitempty"`
+}
+
+type NetworkRoute struct {
+	Family  string `xml:"family,attr,omitempty"`
+	Address string `xml:"address,attr"`
+	Netmask string `xml:"netmask,attr,omitempty"`
+	Prefix  uint   `xml:"prefix,attr,omitempty"`
+	Gateway string `xml:"gateway,attr"`
+	Metric  uint   `xml:"metric,attr,omitempty"`
+}
+
+type NetworkPortGroup struct {
+	Name                string              `xml:"name,attr"`
+	Default             string              `xml:"default,attr,omitempty"`
+	TrustGuestRxFilters string              `xml:"trustGuestRxFilters,attr,omitempty"`
+	VirtualPort         *NetworkVirtualPort `xml:"virtualport"`
+	Bandwidth           *NetworkBandwidth   `xml:"bandwidth"`
+	VLAN                *NetworkVLAN        `xml:"vlan"`
+}
+
+type NetworkVirtualPort struct {
+	Type       string                        `xml:"type,attr,omitempty"`
+	Parameters *NetworkVirtualPortParameters `xml:"parameters"`
+}
+
+type NetworkVirtualPortParameters struct {
+	ManagerID     string `xml:"managerid,attr,omitempty"`
+	TypeID        string `xml:"typeid,attr,omitempty"`
+	TypeIDVersion string `xml:"typeidversion,attr,omitempty"`
+	InstanceID    string `xml:"instanceid,attr,omitempty"`
+	ProfileID     string `xml:"profileid,attr,omitempty"`
+	InterfaceID   string `xml:"interfaceid,attr,omitempty"`
+}
+
+type NetworkBandwidth struct {
+	Inbound  *NetworkBandwidthParams `xml:"inbound"`
+	Outbound *NetworkBandwidthParams `xml:"outbound"`
+}
+
+// NetworkBandwidthParams carries rates in kilobytes/s and burst sizes in
+// kilobytes, as libvirt expects them. Floor is only valid for inbound.
+type NetworkBandwidthParams struct {
+	Average uint `xml:"average,attr,omitempty"`
+	Peak    uint `xml:"peak,attr,omitempty"`
+	Burst   uint `xml:"burst,attr,omitempty"`
+	Floor   uint `xml:"floor,attr,omitempty"`
+}
+
+type NetworkVLAN struct {
+	Trunk string           `xml:"trunk,attr,omitempty"`
+	Tags  []NetworkVLANTag `xml:"tag"`
+}
+
+type NetworkVLANTag struct {
+	ID         uint   `xml:"id,attr"`
+	NativeMode string `xml:"nativeMode,attr,omitempty"`
+}
+
+func (n *NetworkXML) Marshal() (string, error) {
+	doc, err := xml.MarshalIndent(n, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(doc), nil
+}
+
+func (n *NetworkXML) Unmarshal(doc string) error {
+	return xml.Unmarshal([]byte(doc), n)
+}
+
+// NetworkBuilder assembles the common virtual network topologies. The first
+// error hit while building is kept and reported by Build.
+type NetworkBuilder struct {
+	def NetworkXML
+	err error
+}
+
+func NewNetworkBuilder(name string) *NetworkBuilder {
+	return &NetworkBuilder{def: NetworkXML{Name: name}}
+}
+
+// Isolated drops any <forward> element so guests can only reach each other
+// and the host.
+func (b *NetworkBuilder) Isolated() *NetworkBuilder {
+	b.def.Forward = nil
+	return b
+}
+
+// NAT masquerades guest traffic out of dev, or out of whatever interface
+// holds the default route when dev is empty.
+func (b *NetworkBuilder) NAT(dev string) *NetworkBuilder {
+	b.def.Forward = &NetworkForward{Mode: NETWORK_FORWARD_NAT, Dev: dev}
+	return b
+}
+
+// Routed forwards guest traffic out of dev without address translation.
+func (b *NetworkBuilder) Routed(dev string) *NetworkBuilder {
+	b.def.Forward = &NetworkForward{Mode: NETWORK_FORWARD_ROUTE, Dev: dev}
+	return b
+}
+
+// Open forwards guest traffic without libvirt adding any firewall rules.
+// It requires libvirt 2.2.0 or later.
+func (b *NetworkBuilder) Open() *NetworkBuilder {
+	b.def.Forward = &NetworkForward{Mode: NETWORK_FORWARD_OPEN}
+	return b
+}
+
+// HostBridge attaches guests to an existing host bridge, such as one
+// managed by NetworkManager or Open vSwitch. Libvirt does not assign
+// addresses on such networks, so any Subnet is discarded.
+func (b *NetworkBuilder) HostBridge(bridge string) *NetworkBuilder {
+	b.def.Forward = &NetworkForward{Mode: NETWORK_FORWARD_BRIDGE}
+	b.def.Bridge = &NetworkBridge{Name: bridge}
+	b.def.IPs = nil
+	return b
+}
+
+func (b *NetworkBuilder) Bridge(name string) *NetworkBuilder {
+	if b.def.Bridge == nil {
+		b.def.Bridge = &NetworkBridge{}
+	}
+	b.def.Bridge.Name = name
+	return b
+}
+
+func (b *NetworkBuilder) MAC(address string) *NetworkBuilder {
+	if _, err := net.ParseMAC(address); err != nil {
+		b.fail(err)
+		return b
+	}
+	b.def.MAC = &NetworkMAC{Address: address}
+	return b
+}
+
+// Subnet adds an address block in CIDR notation, e.g. "192.168.122.1/24".
+// The address part becomes the host side of the network.
+func (b *NetworkBuilder) Subnet(cidr string) *NetworkBuilder {
+	ip, ipnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		b.fail(err)
+		return b
+	}
+	ones, _ := ipnet.Mask.Size()
+	def := NetworkIP{Address: ip.String()}
+	if ip.To4() != nil {
+		def.Netmask = net.IP(ipnet.Mask).String()
+	} else {
+		def.Family = "ipv6"
+		def.Prefix = uint(ones)
+	}
+	b.def.IPs = append(b.def.IPs, def)
+	return b
+}
+
+// DHCPRange hands out leases between start and end from the subnet that
+// contains them.
+func (b *NetworkBuilder) DHCPRange(start, end string) *NetworkBuilder {
+	ip := b.ipFor(start)
+	if ip == nil {
+		return b
+	}
+	if ip.DHCP == nil {
+		ip.DHCP = &NetworkDHCP{}
+	}
+	ip.DHCP.Ranges = append(ip.DHCP.Ranges, NetworkDHCPRange{Start: start, End: end})
+	return b
+}
+
+// DHCPHost reserves address for the guest with the given MAC address.
+func (b *NetworkBuilder) DHCPHost(mac, name, address string) *NetworkBuilder {
+	ip := b.ipFor(address)
+	if ip == nil {
+		return b
+	}
+	if ip.DHCP == nil {
+		ip.DHCP = &NetworkDHCP{}
+	}
+	ip.DHCP.Hosts = append(ip.DHCP.Hosts, NetworkDHCPHost{MAC: mac, Name: name, IP: address})
+	return b
+}
+
+func (b *NetworkBuilder) DNSHost(address string, hostnames ...string) *NetworkBuilder {
+	if net.ParseIP(address) == nil {
+		b.fail(fmt.Errorf("invalid DNS host address %q", address))
+		return b
+	}
+	b.dns().Hosts = append(b.dns().Hosts, NetworkDNSHost{IP: address, Hostnames: hostnames})
+	return b
+}
+
+func (b *NetworkBuilder) DNSTXT(name, value string) *NetworkBuilder {
+	b.dns().TXTs = append(b.dns().TXTs, NetworkDNSTXT{Name: name, Value: value})
+	return b
+}
+
+func (b *NetworkBuilder) DNSSRV(srv NetworkDNSSRV) *NetworkBuilder {
+	b.dns().SRVs = append(b.dns().SRVs, srv)
+	return b
+}
+
+// Route adds a static route to cidr via gateway, which must be reachable
+// through one of the network's subnets.
+func (b *NetworkBuilder) Route(cidr, gateway string) *NetworkBuilder {
+	_, ipnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		b.fail(err)
+		return b
+	}
+	ones, _ := ipnet.Mask.Size()
+	route := NetworkRoute{Address: ipnet.IP.String(), Prefix: uint(ones), Gateway: gateway}
+	if ipnet.IP.To4() == nil {
+		route.Family = "ipv6"
+	}
+	b.def.Routes = append(b.def.Routes, route)
+	return b
+}
+
+func (b *NetworkBuilder) Bandwidth(inbound, outbound *NetworkBandwidthParams) *NetworkBuilder {
+	b.def.Bandwidth = &NetworkBandwidth{Inbound: inbound, Outbound: outbound}
+	return b
+}
+
+func (b *NetworkBuilder) PortGroup(pg NetworkPortGroup) *NetworkBuilder {
+	b.def.PortGroups = append(b.def.PortGroups, pg)
+	return b
+}
+
+func (b *NetworkBuilder) Build() (*NetworkXML, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if b.def.Name == "" {
+		return nil, errors.New("network name is required")
+	}
+	if b.def.Forward != nil && b.def.Forward.Mode == NETWORK_FORWARD_BRIDGE {
+		if b.def.Bridge == nil || b.def.Bridge.Name == "" {
+			return nil, errors.New("bridge forwarding needs a host bridge name")
+		}
+	}
+	def := b.def
+	return &def, nil
+}
+
+func (b *NetworkBuilder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+func (b *NetworkBuilder) dns() *NetworkDNS {
+	if b.def.DNS == nil {
+		b.def.DNS = &NetworkDNS{}
+	}
+	return b.def.DNS
+}
+
+// ipFor returns the subnet definition containing address.
+func (b *NetworkBuilder) ipFor(address string) *NetworkIP {
+	addr := net.ParseIP(address)
+	if addr == nil {
+		b.fail(fmt.Errorf("invalid address %q", address))
+		return nil
+	}
+	for i := range b.def.IPs {
+		def := &b.def.IPs[i]
+		var mask net.IPMask
+		if def.Netmask != "" {
+			mask = net.IPMask(net.ParseIP(def.Netmask).To4())
+		} else {
+			mask = net.CIDRMask(int(def.Prefix), 128)
+		}
+		ipnet := net.IPNet{IP: net.ParseIP(def.Address).Mask(mask), Mask: mask}
+		if ipnet.Contains(addr) {
+			return def
+		}
+	}
+	b.fail(fmt.Errorf("%s is not inside any subnet of network %s", address, b.def.Name))
+	return nil
+}
@@ -0,0 +1,145 @@
+package libvirt
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testNetworkFullXML = `<network ipv6="yes">
+  <name>full</name>
+  <uuid>81ff0d90-c91e-6742-64da-4a736edb9a9b</uuid>
+  <forward mode="nat" dev="eth0">
+    <nat>
+      <address start="1.2.3.4" end="1.2.3.10"></address>
+      <port start="500" end="1000"></port>
+    </nat>
+  </forward>
+  <bridge name="virbr5" stp="on" delay="0"></bridge>
+  <mac address="52:54:00:e2:3f:10"></mac>
+  <domain name="example.com" localOnly="yes"></domain>
+  <dns>
+    <txt name="example" value="example value"></txt>
+    <host ip="192.168.122.2">
+      <hostname>myhost</hostname>
+      <hostname>myhostalias</hostname>
+    </host>
+    <srv service="name" protocol="tcp" domain="test-domain-name" target="." port="1024" priority="10" weight="10"></srv>
+  </dns>
+  <virtualport type="openvswitch"></virtualport>
+  <bandwidth>
+    <inbound average="1000" peak="5000" burst="5120" floor="200"></inbound>
+    <outbound average="1000" peak="5000" burst="5120"></outbound>
+  </bandwidth>
+  <portgroup name="engineering" default="yes">
+    <virtualport type="802.1Qbh">
+      <parameters profileid="test"></parameters>
+    </virtualport>
+    <bandwidth>
+      <inbound average="1000" peak="5000" burst="5120"></inbound>
+    </bandwidth>
+  </portgroup>
+  <ip address="192.168.122.1" netmask="255.255.255.0">
+    <tftp root="/var/lib/tftp"></tftp>
+    <dhcp>
+      <range start="192.168.122.100" end="192.168.122.254"></range>
+      <host mac="00:16:3e:77:e2:ed" name="foo.example.com" ip="192.168.122.10"></host>
+      <bootp file="pxelinux.0" server="192.168.122.1"></bootp>
+    </dhcp>
+  </ip>
+  <ip family="ipv6" address="2001:db8:ca2:2::1" prefix="64"></ip>
+  <route address="192.168.222.0" prefix="24" gateway="192.168.122.2"></route>
+</network>`
+
+func TestNetworkXMLRoundTrip(t *testing.T) {
+	var def NetworkXML
+	if err := def.Unmarshal(testNetworkFullXML); err != nil {
+		t.Fatal(err)
+	}
+	if def.Forward == nil || def.Forward.Mode != NETWORK_FORWARD_NAT || len(def.Forward.NAT.Ports) != 1 {
+		t.Fatalf("forward not parsed: %+v", def.Forward)
+	}
+	if len(def.IPs) != 2 || len(def.IPs[0].DHCP.Hosts) != 1 || def.IPs[1].Prefix != 64 {
+		t.Fatalf("ip not parsed: %+v", def.IPs)
+	}
+	if def.Bandwidth.Inbound.Floor != 200 || def.PortGroups[0].VirtualPort.Parameters.ProfileID != "test" {
+		t.Fatalf("bandwidth/portgroup not parsed: %+v", def)
+	}
+	if len(def.DNS.Hosts[0].Hostnames) != 2 || def.DNS.SRVs[0].Port != 1024 {
+		t.Fatalf("dns not parsed: %+v", def.DNS)
+	}
+	doc, err := def.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var again NetworkXML
+	if err := again.Unmarshal(doc); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(def, again) {
+		t.Fatalf("round trip mismatch:\n%+v\n%+v", def, again)
+	}
+}
+
+func TestNetworkBuilderErrors(t *testing.T) {
+	if _, err := NewNetworkBuilder("bad").Subnet("10.0.0.1").Build(); err == nil {
+		t.Error("expected error for subnet without prefix")
+	}
+	if _, err := NewNetworkBuilder("bad").Subnet("10.0.0.1/24").DHCPRange("10.1.0.2", "10.1.0.9").Build(); err == nil {
+		t.Error("expected error for DHCP range outside subnet")
+	}
+	if _, err := NewNetworkBuilder("").Isolated().Build(); err == nil {
+		t.Error("expected error for missing name")
+	}
+}
+
+func TestNetworkBuilderTopologies(t *testing.T) {
+	conn := buildTestConnection()
+	defer func() {
+		if res, _ := conn.CloseConnection(); res != 0 {
+			t.Errorf("CloseConnection() == %d, expected 0", res)
+		}
+	}()
+	builders := []*NetworkBuilder{
+		NewNetworkBuilder("isolated-net").Isolated().Bridge("virbr10").
+			Subnet("10.10.0.1/24").DHCPRange("10.10.0.100", "10.10.0.200").
+			DHCPHost("52:54:00:00:00:01", "vm1", "10.10.0.5"),
+		NewNetworkBuilder("nat-net").NAT("").Bridge("virbr11").
+			Subnet("10.11.0.1/24").DHCPRange("10.11.0.100", "10.11.0.200").
+			DNSHost("10.11.0.5", "vm1", "vm1.example.com").DNSTXT("info", "nat network"),
+		NewNetworkBuilder("routed-net").Routed("eth0").Bridge("virbr12").
+			Subnet("10.12.0.1/24").Subnet("fd00:12::1/64").
+			Route("10.112.0.0/16", "10.12.0.2"),
+		NewNetworkBuilder("bridge-net").HostBridge("br0"),
+	}
+	for _, b := range builders {
+		def, err := b.Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		doc, err := def.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		net, err := conn.NetworkDefineXML(doc)
+		if err != nil {
+			t.Fatalf("%s: %v\n%s", def.Name, err, doc)
+		}
+		desc, err := net.GetXMLDesc(0)
+		if err != nil {
+			t.Error(err)
+		}
+		var got NetworkXML
+		if err := got.Unmarshal(desc); err != nil {
+			t.Error(err)
+		}
+		if got.Name != def.Name || len(got.IPs) != len(def.IPs) || len(got.Routes) != len(def.Routes) {
+			t.Errorf("%s: defined network does not match:\n%s", def.Name, desc)
+		}
+		if (got.Forward == nil) != (def.Forward == nil) ||
+			(def.Forward != nil && got.Forward.Mode != def.Forward.Mode) {
+			t.Errorf("%s: forward mode mismatch:\n%s", def.Name, desc)
+		}
+		net.Undefine()
+		net.Free()
+	}
+}
@@ -0,0 +1,881 @@
+package libvirt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// NWFilterResolver returns the definition of a filter referenced by name.
+type NWFilterResolver func(name string) (*NWFilterXML, error)
+
+// NWFilterMapResolver resolves filter references from XML documents keyed
+// by filter name, e.g. files checked into a repository.
+func NWFilterMapResolver(filters map[string]string) NWFilterResolver {
+	return func(name string) (*NWFilterXML, error) {
+		doc, ok := filters[name]
+		if !ok {
+			return nil, fmt.Errorf("nwfilter %s not found", name)
+		}
+		filter := &NWFilterXML{}
+		if err := filter.Unmarshal(doc); err != nil {
+			return nil, err
+		}
+		return filter, nil
+	}
+}
+
+// NWFilterResolver resolves filter references from the filters defined on
+// the connection.
+func (c *VirConnection) NWFilterResolver() NWFilterResolver {
+	return func(name string) (*NWFilterXML, error) {
+		f, err := c.LookupNWFilterByName(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Free()
+		doc, err := f.GetXMLDesc(0)
+		if err != nil {
+			return nil, err
+		}
+		filter := &NWFilterXML{}
+		if err := filter.Unmarshal(doc); err != nil {
+			return nil, err
+		}
+		return filter, nil
+	}
+}
+
+// NWFilterPacket describes a frame crossing a guest interface. Direction is
+// NWFILTER_DIRECTION_IN for traffic towards the guest and
+// NWFILTER_DIRECTION_OUT for traffic sent by it. Addresses and ports always
+// refer to the fields as they appear in the packet.
+type NWFilterPacket struct {
+	Direction string
+	SrcMAC    string
+	DstMAC    string
+	// EtherType is "ipv4", "ipv6", "arp", "rarp" or a number such as
+	// "0x835". It is derived from SrcIP when empty.
+	EtherType string
+
+	ARPOpcode string
+	ARPSrcMAC string
+	ARPDstMAC string
+	ARPSrcIP  string
+	ARPDstIP  string
+
+	SrcIP string
+	DstIP string
+	// Protocol is the IP protocol, e.g. "tcp", "udp", "icmp" or a number.
+	Protocol string
+	SrcPort  int
+	DstPort  int
+	ICMPType int
+	ICMPCode int
+	// State is the connection tracking state: NEW, ESTABLISHED, RELATED,
+	// INVALID or NONE.
+	State string
+}
+
+// NWFilterMatchedRule is a rule that matched while evaluating a packet.
+type NWFilterMatchedRule struct {
+	Filter string
+	Chain  string
+	Rule   *NWFilterRule
+}
+
+// NWFilterVerdict is the outcome of evaluating a packet. Rule is the rule
+// that decided Action, or nil if no terminating rule matched and the packet
+// was accepted by default.
+type NWFilterVerdict struct {
+	Action  string
+	Filter  string
+	Chain   string
+	Rule    *NWFilterRule
+	Matched []NWFilterMatchedRule
+}
+
+// NWFilterEvaluator evaluates packets against an instantiated filter tree
+// the way libvirt's ebtables/iptables driver lays it out:
+//
+// Rules without a protocol and mac, arp, rarp, ip and ipv6 rules are layer 2
+// (ebtables) rules. They are placed in the chain of the filter defining
+// them; the root chain jumps to every other chain, for frames of the
+// chain's protocol, ordered by the chain priority. Within a chain, rules
+// and jumps are ordered by priority, ties keeping definition order. An
+// accept, drop or reject ends layer 2 processing; return goes back to the
+// root chain, or ends processing when already there.
+//
+// tcp, udp, sctp, icmp, igmp and all rules (and their ipv6 variants) are
+// layer 3 (iptables) rules, evaluated in one list ordered by priority
+// whatever their chain. accept, drop, reject and return end processing.
+//
+// A packet is dropped if either layer drops or rejects it. continue only
+// records the match. Packets no rule decides on are accepted. Connection
+// tracking rules libvirt adds implicitly are not modeled; state is only
+// compared when a rule sets it explicitly.
+type NWFilterEvaluator struct {
+	root   []nwfilterEntry
+	chains map[string][]nwfilterEntry
+	l3     []nwfilterEntry
+}
+
+type nwfilterEntry struct {
+	priority int
+	seq      int
+	// jump is the chain to jump to, or "" for a rule.
+	jump      string
+	filter    string
+	chain     string
+	rule      *NWFilterRule
+	proto     string
+	instances []NWFilterMatch
+}
+
+// Default priorities of filters, by chain protocol.
+var nwfilterChainPriority = map[string]int{
+	"root": 0,
+	"stp":  -810,
+	"mac":  -800,
+	"vlan": -750,
+	"ipv4": -700,
+	"ipv6": -600,
+	"arp":  -500,
+	"rarp": -400,
+}
+
+var nwfilterLayer2Protocols = map[string]bool{
+	"": true, "mac": true, "arp": true, "rarp": true, "ip": true, "ipv6": true,
+}
+
+var nwfilterLayer3Protocols = map[string]string{
+	"tcp": "ipv4", "udp": "ipv4", "sctp": "ipv4", "icmp": "ipv4", "igmp": "ipv4", "all": "ipv4",
+	"tcp-ipv6": "ipv6", "udp-ipv6": "ipv6", "sctp-ipv6": "ipv6", "icmpv6": "ipv6", "all-ipv6": "ipv6",
+}
+
+// NewNWFilterEvaluator instantiates filter and everything it references
+// through resolve, binding variables such as IP and MAC from vars. A
+// variable with several values instantiates the rules using it once per
+// value, as libvirt does.
+func NewNWFilterEvaluator(filter *NWFilterXML, resolve NWFilterResolver, vars map[string][]string) (*NWFilterEvaluator, error) {
+	b := &nwfilterBuilder{
+		resolve:    resolve,
+		active:     map[string]bool{},
+		eval:       &NWFilterEvaluator{chains: map[string][]nwfilterEntry{}},
+		seenChains: map[string]bool{},
+	}
+	if err := b.instantiate(filter, vars); err != nil {
+		return nil, err
+	}
+	e := b.eval
+	sortNWFilterEntries(e.root)
+	sortNWFilterEntries(e.l3)
+	for _, entries := range e.chains {
+		sortNWFilterEntries(entries)
+	}
+	return e, nil
+}
+
+type nwfilterBuilder struct {
+	resolve    NWFilterResolver
+	active     map[string]bool
+	eval       *NWFilterEvaluator
+	seenChains map[string]bool
+	seq        int
+}
+
+func (b *nwfilterBuilder) instantiate(filter *NWFilterXML, vars map[string][]string) error {
+	if b.active[filter.Name] {
+		return fmt.Errorf("nwfilter %s references itself", filter.Name)
+	}
+	b.active[filter.Name] = true
+	defer delete(b.active, filter.Name)
+
+	chain := filter.Chain
+	if chain == "" {
+		chain = NWFILTER_CHAIN_ROOT
+	}
+	base := strings.SplitN(chain, "-", 2)[0]
+	chainPriority, ok := nwfilterChainPriority[base]
+	if !ok {
+		return fmt.Errorf("nwfilter %s: unknown chain %s", filter.Name, chain)
+	}
+	if base == "stp" || base == "vlan" {
+		return fmt.Errorf("nwfilter %s: %s chains are not supported", filter.Name, base)
+	}
+	if filter.Priority != nil {
+		chainPriority = *filter.Priority
+	}
+	if chain != NWFILTER_CHAIN_ROOT && !b.seenChains[chain] {
+		b.seenChains[chain] = true
+		b.eval.root = append(b.eval.root, nwfilterEntry{priority: chainPriority, seq: b.next(), jump: chain})
+	}
+
+	for _, entry := range filter.Entries {
+		if entry.Ref != nil {
+			if b.resolve == nil {
+				return fmt.Errorf("nwfilter %s: no resolver for filterref %s", filter.Name, entry.Ref.Filter)
+			}
+			child, err := b.resolve(entry.Ref.Filter)
+			if err != nil {
+				return err
+			}
+			childVars := make(map[string][]string, len(vars)+len(entry.Ref.Parameters))
+			for name, values := range vars {
+				childVars[name] = values
+			}
+			overridden := map[string]bool{}
+			for _, param := range entry.Ref.Parameters {
+				if !overridden[param.Name] {
+					childVars[param.Name] = nil
+					overridden[param.Name] = true
+				}
+				childVars[param.Name] = append(childVars[param.Name], param.Value)
+			}
+			if err := b.instantiate(child, childVars); err != nil {
+				return err
+			}
+			continue
+		}
+		if entry.Rule == nil {
+			continue
+		}
+		rule := entry.Rule
+		proto, match := rule.Protocol()
+		if match == nil {
+			match = &NWFilterMatch{}
+		}
+		instances, err := expandNWFilterMatch(*match, vars)
+		if err != nil {
+			return fmt.Errorf("nwfilter %s: %v", filter.Name, err)
+		}
+		priority := 500
+		if rule.Priority != nil {
+			priority = *rule.Priority
+		}
+		inst := nwfilterEntry{
+			priority:  priority,
+			seq:       b.next(),
+			filter:    filter.Name,
+			chain:     chain,
+			rule:      rule,
+			proto:     proto,
+			instances: instances,
+		}
+		switch {
+		case nwfilterLayer2Protocols[proto]:
+			if chain == NWFILTER_CHAIN_ROOT {
+				b.eval.root = append(b.eval.root, inst)
+			} else {
+				b.eval.chains[chain] = append(b.eval.chains[chain], inst)
+			}
+		case nwfilterLayer3Protocols[proto] != "":
+			b.eval.l3 = append(b.eval.l3, inst)
+		default:
+			return fmt.Errorf("nwfilter %s: %s rules are not supported", filter.Name, proto)
+		}
+	}
+	return nil
+}
+
+func (b *nwfilterBuilder) next() int {
+	b.seq++
+	return b.seq
+}
+
+func sortNWFilterEntries(entries []nwfilterEntry) {
+	sort.Sort(nwfilterEntriesByPriority(entries))
+}
+
+type nwfilterEntriesByPriority []nwfilterEntry
+
+func (s nwfilterEntriesByPriority) Len() int      { return len(s) }
+func (s nwfilterEntriesByPriority) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s nwfilterEntriesByPriority) Less(i, j int) bool {
+	if s[i].priority != s[j].priority {
+		return s[i].priority < s[j].priority
+	}
+	return s[i].seq < s[j].seq
+}
+
+var nwfilterVariable = regexp.MustCompile(`^\$([A-Za-z_][A-Za-z0-9_]*)(?:\[(@?)([0-9]+)\])?$`)
+
+// expandNWFilterMatch substitutes variables, producing one match per
+// combination of values. $X iterates over all values of X, $X[@n] iterates
+// in lockstep with every other variable using iterator n, $X[n] picks the
+// n-th value.
+func expandNWFilterMatch(match NWFilterMatch, vars map[string][]string) ([]NWFilterMatch, error) {
+	type ref struct {
+		field int
+		name  string
+		iter  string
+		index int
+	}
+	v := reflect.ValueOf(match)
+	var refs []ref
+	iterLen := map[string]int{}
+	var iters []string
+	for i := 0; i < v.NumField(); i++ {
+		m := nwfilterVariable.FindStringSubmatch(v.Field(i).String())
+		if m == nil {
+			continue
+		}
+		values, ok := vars[m[1]]
+		if !ok || len(values) == 0 {
+			return nil, fmt.Errorf("variable %s has no value", m[1])
+		}
+		r := ref{field: i, name: m[1], index: -1}
+		switch {
+		case m[3] == "":
+			r.iter = "$" + m[1]
+		case m[2] == "@":
+			r.iter = "@" + m[3]
+		default:
+			r.index, _ = strconv.Atoi(m[3])
+			if r.index >= len(values) {
+				return nil, fmt.Errorf("variable %s has no value at index %d", m[1], r.index)
+			}
+		}
+		if r.iter != "" {
+			if n, seen := iterLen[r.iter]; !seen {
+				iters = append(iters, r.iter)
+				iterLen[r.iter] = len(values)
+			} else if len(values) < n {
+				iterLen[r.iter] = len(values)
+			}
+		}
+		refs = append(refs, r)
+	}
+
+	var out []NWFilterMatch
+	pos := make(map[string]int, len(iters))
+	for {
+		inst := reflect.New(v.Type()).Elem()
+		inst.Set(v)
+		for _, r := range refs {
+			values := vars[r.name]
+			index := r.index
+			if r.iter != "" {
+				index = pos[r.iter]
+			}
+			inst.Field(r.field).SetString(values[index])
+		}
+		out = append(out, inst.Interface().(NWFilterMatch))
+
+		i := len(iters) - 1
+		for ; i >= 0; i-- {
+			pos[iters[i]]++
+			if pos[iters[i]] < iterLen[iters[i]] {
+				break
+			}
+			pos[iters[i]] = 0
+		}
+		if i < 0 {
+			return out, nil
+		}
+	}
+}
+
+// Evaluate returns the verdict for pkt.
+func (e *NWFilterEvaluator) Evaluate(pkt NWFilterPacket) (*NWFilterVerdict, error) {
+	if pkt.Direction != NWFILTER_DIRECTION_IN && pkt.Direction != NWFILTER_DIRECTION_OUT {
+		return nil, fmt.Errorf("packet direction must be %q or %q", NWFILTER_DIRECTION_IN, NWFILTER_DIRECTION_OUT)
+	}
+	if pkt.EtherType == "" {
+		if ip := net.ParseIP(pkt.SrcIP); ip != nil {
+			if ip.To4() != nil {
+				pkt.EtherType = "ipv4"
+			} else {
+				pkt.EtherType = "ipv6"
+			}
+		}
+	}
+	etherType, err := nwfilterEtherType(pkt.EtherType)
+	if err != nil {
+		return nil, err
+	}
+	verdict := &NWFilterVerdict{Action: NWFILTER_ACTION_ACCEPT}
+
+	decided, err := e.walk(e.root, &pkt, etherType, verdict, false)
+	if err != nil {
+		return nil, err
+	}
+	if decided && verdict.Action != NWFILTER_ACTION_ACCEPT {
+		return verdict, nil
+	}
+	if etherType != 0x0800 && etherType != 0x86dd {
+		return verdict, nil
+	}
+	l3 := &NWFilterVerdict{Action: NWFILTER_ACTION_ACCEPT, Matched: verdict.Matched}
+	decided, err = e.walk(e.l3, &pkt, etherType, l3, true)
+	if err != nil {
+		return nil, err
+	}
+	if decided {
+		return l3, nil
+	}
+	verdict.Matched = l3.Matched
+	return verdict, nil
+}
+
+// walk evaluates entries, following jumps to layer 2 chains. It reports
+// whether a rule decided the verdict.
+func (e *NWFilterEvaluator) walk(entries []nwfilterEntry, pkt *NWFilterPacket, etherType uint64, verdict *NWFilterVerdict, layer3 bool) (bool, error) {
+	for i := range entries {
+		entry := &entries[i]
+		if entry.jump != "" {
+			if !nwfilterChainApplies(entry.jump, etherType) {
+				continue
+			}
+			decided, err := e.walk(e.chains[entry.jump], pkt, etherType, verdict, false)
+			if err != nil || decided {
+				return decided, err
+			}
+			continue
+		}
+		ok, err := entry.matches(pkt, etherType)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			continue
+		}
+		verdict.Matched = append(verdict.Matched, NWFilterMatchedRule{Filter: entry.filter, Chain: entry.chain, Rule: entry.rule})
+		switch entry.rule.Action {
+		case NWFILTER_ACTION_CONTINUE:
+			continue
+		case NWFILTER_ACTION_RETURN:
+			if !layer3 {
+				return false, nil
+			}
+			verdict.Action = NWFILTER_ACTION_ACCEPT
+		case NWFILTER_ACTION_ACCEPT, NWFILTER_ACTION_DROP, NWFILTER_ACTION_REJECT:
+			verdict.Action = entry.rule.Action
+		default:
+			return false, fmt.Errorf("nwfilter %s: unknown action %q", entry.filter, entry.rule.Action)
+		}
+		verdict.Filter, verdict.Chain, verdict.Rule = entry.filter, entry.chain, entry.rule
+		return true, nil
+	}
+	return false, nil
+}
+
+func nwfilterChainApplies(chain string, etherType uint64) bool {
+	switch strings.SplitN(chain, "-", 2)[0] {
+	case "mac":
+		return true
+	case "arp":
+		return etherType == 0x0806
+	case "rarp":
+		return etherType == 0x8035
+	case "ipv4":
+		return etherType == 0x0800
+	case "ipv6":
+		return etherType == 0x86dd
+	}
+	return false
+}
+
+func (entry *nwfilterEntry) matches(pkt *NWFilterPacket, etherType uint64) (bool, error) {
+	dir := entry.rule.Direction
+	if dir == "" {
+		dir = NWFILTER_DIRECTION_INOUT
+	}
+	if dir != NWFILTER_DIRECTION_INOUT && dir != pkt.Direction {
+		return false, nil
+	}
+	switch entry.proto {
+	case "arp":
+		if etherType != 0x0806 {
+			return false, nil
+		}
+	case "rarp":
+		if etherType != 0x8035 {
+			return false, nil
+		}
+	case "ip":
+		if etherType != 0x0800 {
+			return false, nil
+		}
+	case "ipv6":
+		if etherType != 0x86dd {
+			return false, nil
+		}
+	case "", "mac":
+	default:
+		want := uint64(0x0800)
+		if nwfilterLayer3Protocols[entry.proto] == "ipv6" {
+			want = 0x86dd
+		}
+		if etherType != want {
+			return false, nil
+		}
+		if proto := strings.TrimSuffix(entry.proto, "-ipv6"); proto != "all" {
+			if proto == "icmpv6" {
+				proto = "ipv6-icmp"
+			}
+			ok, err := nwfilterProtocolMatches(proto, pkt.Protocol)
+			if !ok || err != nil {
+				return false, err
+			}
+		}
+	}
+	for i := range entry.instances {
+		ok, err := nwfilterMatchAttrs(&entry.instances[i], entry.proto, pkt, etherType)
+		if ok || err != nil {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+// nwfilterMatchAttrs checks every attribute of m; match='no' negates each
+// attribute individually, as libvirt does.
+func nwfilterMatchAttrs(m *NWFilterMatch, proto string, pkt *NWFilterPacket, etherType uint64) (bool, error) {
+	negate := m.Match == "no"
+	check := func(set bool, ok bool, err error) error {
+		if !set || err != nil {
+			return err
+		}
+		if ok == negate {
+			return errNWFilterNoMatch
+		}
+		return nil
+	}
+	unsupported := map[string]string{
+		"dscp": m.DSCP, "flags": m.Flags, "ipset": m.IPSet, "ipsetflags": m.IPSetFlags,
+		"connlimit-above": m.ConnLimitAbove, "vlanid": m.VLANID, "encap-protocol": m.EncapProtocol,
+		"hwtype": m.HWType, "protocoltype": m.ProtocolType, "gratuitous": m.Gratuitous,
+	}
+	for attr, value := range unsupported {
+		if value != "" {
+			return false, fmt.Errorf("attribute %s is not supported by the evaluator", attr)
+		}
+	}
+
+	srcIP, dstIP := pkt.SrcIP, pkt.DstIP
+	srcMAC, dstMAC := pkt.SrcMAC, pkt.DstMAC
+	if proto == "arp" || proto == "rarp" {
+		srcIP, dstIP = pkt.ARPSrcIP, pkt.ARPDstIP
+	}
+	srcIPMask, dstIPMask := m.SrcIPMask, m.DstIPMask
+	if proto == "arp" || proto == "rarp" {
+		srcIPMask, dstIPMask = m.ARPSrcIPMask, m.ARPDstIPMask
+	}
+
+	var err error
+	ok, e := nwfilterMACMatches(m.SrcMACAddr, m.SrcMACMask, srcMAC)
+	if err = check(m.SrcMACAddr != "", ok, e); err != nil {
+		return nwfilterResult(err)
+	}
+	ok, e = nwfilterMACMatches(m.DstMACAddr, m.DstMACMask, dstMAC)
+	if err = check(m.DstMACAddr != "", ok, e); err != nil {
+		return nwfilterResult(err)
+	}
+	if m.ProtocolID != "" {
+		want, e := nwfilterEtherType(m.ProtocolID)
+		if err = check(true, want == etherType, e); err != nil {
+			return nwfilterResult(err)
+		}
+	}
+	if m.Opcode != "" {
+		ok, e := nwfilterARPOpcodeMatches(m.Opcode, pkt.ARPOpcode)
+		if err = check(true, ok, e); err != nil {
+			return nwfilterResult(err)
+		}
+	}
+	ok, e = nwfilterMACMatches(m.ARPSrcMACAddr, "", pkt.ARPSrcMAC)
+	if err = check(m.ARPSrcMACAddr != "", ok, e); err != nil {
+		return nwfilterResult(err)
+	}
+	ok, e = nwfilterMACMatches(m.ARPDstMACAddr, "", pkt.ARPDstMAC)
+	if err = check(m.ARPDstMACAddr != "", ok, e); err != nil {
+		return nwfilterResult(err)
+	}
+	srcAddr, dstAddr := m.SrcIPAddr, m.DstIPAddr
+	if proto == "arp" || proto == "rarp" {
+		srcAddr, dstAddr = m.ARPSrcIPAddr, m.ARPDstIPAddr
+	}
+	ok, e = nwfilterIPMatches(srcAddr, srcIPMask, srcIP)
+	if err = check(srcAddr != "", ok, e); err != nil {
+		return nwfilterResult(err)
+	}
+	ok, e = nwfilterIPMatches(dstAddr, dstIPMask, dstIP)
+	if err = check(dstAddr != "", ok, e); err != nil {
+		return nwfilterResult(err)
+	}
+	ok, e = nwfilterIPRangeMatches(m.SrcIPFrom, m.SrcIPTo, pkt.SrcIP)
+	if err = check(m.SrcIPFrom != "" || m.SrcIPTo != "", ok, e); err != nil {
+		return nwfilterResult(err)
+	}
+	ok, e = nwfilterIPRangeMatches(m.DstIPFrom, m.DstIPTo, pkt.DstIP)
+	if err = check(m.DstIPFrom != "" || m.DstIPTo != "", ok, e); err != nil {
+		return nwfilterResult(err)
+	}
+	if m.Protocol != "" {
+		ok, e := nwfilterProtocolMatches(m.Protocol, pkt.Protocol)
+		if err = check(true, ok, e); err != nil {
+			return nwfilterResult(err)
+		}
+	}
+	ok, e = nwfilterPortMatches(m.SrcPortStart, m.SrcPortEnd, pkt.SrcPort)
+	if err = check(m.SrcPortStart != "" || m.SrcPortEnd != "", ok, e); err != nil {
+		return nwfilterResult(err)
+	}
+	ok, e = nwfilterPortMatches(m.DstPortStart, m.DstPortEnd, pkt.DstPort)
+	if err = check(m.DstPortStart != "" || m.DstPortEnd != "", ok, e); err != nil {
+		return nwfilterResult(err)
+	}
+	if m.Type != "" {
+		ok, e := nwfilterNumberMatches(m.Type, pkt.ICMPType)
+		if err = check(true, ok, e); err != nil {
+			return nwfilterResult(err)
+		}
+	}
+	if m.Code != "" {
+		ok, e := nwfilterNumberMatches(m.Code, pkt.ICMPCode)
+		if err = check(true, ok, e); err != nil {
+			return nwfilterResult(err)
+		}
+	}
+	if m.State != "" {
+		if err = check(true, nwfilterStateMatches(m.State, pkt.State), nil); err != nil {
+			return nwfilterResult(err)
+		}
+	}
+	return true, nil
+}
+
+var errNWFilterNoMatch = errors.New("no match")
+
+func nwfilterResult(err error) (bool, error) {
+	if err == errNWFilterNoMatch {
+		return false, nil
+	}
+	return false, err
+}
+
+var nwfilterEtherTypes = map[string]uint64{
+	"ipv4": 0x0800,
+	"ipv6": 0x86dd,
+	"arp":  0x0806,
+	"rarp": 0x8035,
+	"vlan": 0x8100,
+}
+
+func nwfilterEtherType(value string) (uint64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	if n, ok := nwfilterEtherTypes[strings.ToLower(value)]; ok {
+		return n, nil
+	}
+	n, err := strconv.ParseUint(value, 0, 16)
+	if err != nil {
+		return 0, fmt.Errorf("invalid protocol id %q", value)
+	}
+	return n, nil
+}
+
+var nwfilterIPProtocols = map[string]uint64{
+	"icmp": 1, "igmp": 2, "tcp": 6, "udp": 17, "esp": 50, "ah": 51,
+	"ipv6-icmp": 58, "icmpv6": 58, "sctp": 132, "udplite": 136,
+}
+
+func nwfilterProtocolMatches(want, got string) (bool, error) {
+	if got == "" {
+		return false, nil
+	}
+	parse := func(s string) (uint64, error) {
+		if n, ok := nwfilterIPProtocols[strings.ToLower(s)]; ok {
+			return n, nil
+		}
+		n, err := strconv.ParseUint(s, 0, 8)
+		if err != nil {
+			return 0, fmt.Errorf("invalid IP protocol %q", s)
+		}
+		return n, nil
+	}
+	w, err := parse(want)
+	if err != nil {
+		return false, err
+	}
+	g, err := parse(got)
+	if err != nil {
+		return false, err
+	}
+	return w == g, nil
+}
+
+var nwfilterARPOpcodes = map[string]uint64{
+	"request": 1, "reply": 2, "request_reverse": 3, "reply_reverse": 4,
+	"drarp_request": 5, "drarp_reply": 6, "drarp_error": 7,
+	"inarp_request": 8, "arp_nak": 10,
+}
+
+func nwfilterARPOpcodeMatches(want, got string) (bool, error) {
+	if got == "" {
+		return false, nil
+	}
+	parse := func(s string) (uint64, error) {
+		if n, ok := nwfilterARPOpcodes[strings.ToLower(s)]; ok {
+			return n, nil
+		}
+		n, err := strconv.ParseUint(s, 0, 16)
+		if err != nil {
+			return 0, fmt.Errorf("invalid ARP opcode %q", s)
+		}
+		return n, nil
+	}
+	w, err := parse(want)
+	if err != nil {
+		return false, err
+	}
+	g, err := parse(got)
+	if err != nil {
+		return false, err
+	}
+	return w == g, nil
+}
+
+func nwfilterMACMatches(addr, mask, got string) (bool, error) {
+	if addr == "" {
+		return true, nil
+	}
+	want, err := net.ParseMAC(addr)
+	if err != nil {
+		return false, fmt.Errorf("invalid MAC address %q", addr)
+	}
+	if got == "" {
+		return false, nil
+	}
+	mac, err := net.ParseMAC(got)
+	if err != nil {
+		return false, fmt.Errorf("invalid packet MAC address %q", got)
+	}
+	bitmask := net.HardwareAddr{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	if mask != "" {
+		if bitmask, err = net.ParseMAC(mask); err != nil {
+			return false, fmt.Errorf("invalid MAC mask %q", mask)
+		}
+	}
+	if len(want) != len(mac) || len(bitmask) != len(mac) {
+		return false, nil
+	}
+	for i := range mac {
+		if want[i]&bitmask[i] != mac[i]&bitmask[i] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func nwfilterIPMatches(addr, mask, got string) (bool, error) {
+	if addr == "" {
+		return true, nil
+	}
+	want := net.ParseIP(addr)
+	if want == nil {
+		return false, fmt.Errorf("invalid IP address %q", addr)
+	}
+	ip := net.ParseIP(got)
+	if ip == nil {
+		return false, nil
+	}
+	bits := 128
+	if want.To4() != nil {
+		want, bits = want.To4(), 32
+	}
+	var ipmask net.IPMask
+	switch {
+	case mask == "":
+		ipmask = net.CIDRMask(bits, bits)
+	case strings.ContainsAny(mask, ".:"):
+		m := net.ParseIP(mask)
+		if m == nil {
+			return false, fmt.Errorf("invalid IP mask %q", mask)
+		}
+		if bits == 32 {
+			m = m.To4()
+		}
+		ipmask = net.IPMask(m)
+	default:
+		n, err := strconv.Atoi(mask)
+		if err != nil || n < 0 || n > bits {
+			return false, fmt.Errorf("invalid IP mask %q", mask)
+		}
+		ipmask = net.CIDRMask(n, bits)
+	}
+	ipnet := net.IPNet{IP: want.Mask(ipmask), Mask: ipmask}
+	return ipnet.Contains(ip), nil
+}
+
+func nwfilterIPRangeMatches(from, to, got string) (bool, error) {
+	ip := net.ParseIP(got)
+	if ip == nil {
+		return false, nil
+	}
+	ip = ip.To16()
+	if from != "" {
+		lo := net.ParseIP(from)
+		if lo == nil {
+			return false, fmt.Errorf("invalid IP address %q", from)
+		}
+		if bytes.Compare(ip, lo.To16()) < 0 {
+			return false, nil
+		}
+	}
+	if to != "" {
+		hi := net.ParseIP(to)
+		if hi == nil {
+			return false, fmt.Errorf("invalid IP address %q", to)
+		}
+		if bytes.Compare(ip, hi.To16()) > 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func nwfilterPortMatches(start, end string, got int) (bool, error) {
+	if start == "" {
+		start = "0"
+	}
+	lo, err := strconv.ParseUint(start, 0, 16)
+	if err != nil {
+		return false, fmt.Errorf("invalid port %q", start)
+	}
+	hi := lo
+	if end != "" {
+		if hi, err = strconv.ParseUint(end, 0, 16); err != nil {
+			return false, fmt.Errorf("invalid port %q", end)
+		}
+	}
+	return uint64(got) >= lo && uint64(got) <= hi, nil
+}
+
+func nwfilterNumberMatches(want string, got int) (bool, error) {
+	n, err := strconv.ParseUint(want, 0, 8)
+	if err != nil {
+		return false, fmt.Errorf("invalid number %q", want)
+	}
+	return uint64(got) == n, nil
+}
+
+func nwfilterStateMatches(states, got string) bool {
+	if got == "" {
+		got = "NONE"
+	}
+	for _, state := range strings.Split(states, ",") {
+		if strings.EqualFold(strings.TrimSpace(state), got) {
+			return true
+		}
+	}
+	return false
+}
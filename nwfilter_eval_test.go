@@ -0,0 +1,211 @@
+package libvirt
+
+import (
+	"testing"
+)
+
+func buildTestCleanTrafficEvaluator(t *testing.T, ips ...string) *NWFilterEvaluator {
+	resolve := NWFilterMapResolver(testBuiltinNWFilters)
+	root, err := resolve("clean-traffic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	vars := map[string][]string{"MAC": {"52:54:00:aa:bb:cc"}, "IP": ips}
+	eval, err := NewNWFilterEvaluator(root, resolve, vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return eval
+}
+
+func TestNWFilterEvaluateCleanTraffic(t *testing.T) {
+	eval := buildTestCleanTrafficEvaluator(t, "10.0.0.5", "10.0.0.6")
+	cases := []struct {
+		name   string
+		pkt    NWFilterPacket
+		action string
+		filter string
+	}{
+		{"outgoing from guest address", NWFilterPacket{
+			Direction: NWFILTER_DIRECTION_OUT, SrcMAC: "52:54:00:aa:bb:cc",
+			SrcIP: "10.0.0.5", DstIP: "8.8.8.8", Protocol: "udp", DstPort: 53,
+		}, NWFILTER_ACTION_ACCEPT, "clean-traffic"},
+		{"outgoing from second guest address", NWFilterPacket{
+			Direction: NWFILTER_DIRECTION_OUT, SrcMAC: "52:54:00:aa:bb:cc",
+			SrcIP: "10.0.0.6", DstIP: "8.8.8.8", Protocol: "tcp", DstPort: 443,
+		}, NWFILTER_ACTION_ACCEPT, "clean-traffic"},
+		{"outgoing DHCP discover", NWFilterPacket{
+			Direction: NWFILTER_DIRECTION_OUT, SrcMAC: "52:54:00:aa:bb:cc",
+			SrcIP: "0.0.0.0", DstIP: "255.255.255.255", Protocol: "udp", SrcPort: 68, DstPort: 67,
+		}, NWFILTER_ACTION_ACCEPT, "clean-traffic"},
+		{"spoofed IP", NWFilterPacket{
+			Direction: NWFILTER_DIRECTION_OUT, SrcMAC: "52:54:00:aa:bb:cc",
+			SrcIP: "10.0.0.99", DstIP: "8.8.8.8", Protocol: "udp", DstPort: 53,
+		}, NWFILTER_ACTION_DROP, "no-ip-spoofing"},
+		{"spoofed MAC", NWFilterPacket{
+			Direction: NWFILTER_DIRECTION_OUT, SrcMAC: "52:54:00:00:00:01",
+			SrcIP: "10.0.0.5", DstIP: "8.8.8.8", Protocol: "udp", DstPort: 53,
+		}, NWFILTER_ACTION_DROP, "no-mac-spoofing"},
+		{"incoming IPv4", NWFilterPacket{
+			Direction: NWFILTER_DIRECTION_IN, DstMAC: "52:54:00:aa:bb:cc",
+			SrcIP: "192.168.1.1", DstIP: "10.0.0.5", Protocol: "tcp", DstPort: 22,
+		}, NWFILTER_ACTION_ACCEPT, "allow-incoming-ipv4"},
+		{"outgoing IPv6", NWFilterPacket{
+			Direction: NWFILTER_DIRECTION_OUT, SrcMAC: "52:54:00:aa:bb:cc",
+			SrcIP: "fd00::5", DstIP: "fd00::1", Protocol: "tcp", DstPort: 22,
+		}, NWFILTER_ACTION_DROP, "no-other-l2-traffic"},
+		{"outgoing ARP", NWFilterPacket{
+			Direction: NWFILTER_DIRECTION_OUT, SrcMAC: "52:54:00:aa:bb:cc", EtherType: "arp",
+			ARPOpcode: "Request", ARPSrcMAC: "52:54:00:aa:bb:cc", ARPSrcIP: "10.0.0.5", ARPDstIP: "10.0.0.1",
+		}, NWFILTER_ACTION_ACCEPT, "clean-traffic"},
+		{"ARP poisoning", NWFilterPacket{
+			Direction: NWFILTER_DIRECTION_OUT, SrcMAC: "52:54:00:aa:bb:cc", EtherType: "arp",
+			ARPOpcode: "Reply", ARPSrcMAC: "52:54:00:aa:bb:cc", ARPSrcIP: "10.0.0.1", ARPDstIP: "10.0.0.7",
+		}, NWFILTER_ACTION_DROP, "no-arp-ip-spoofing"},
+		{"RARP self announce", NWFilterPacket{
+			Direction: NWFILTER_DIRECTION_OUT, SrcMAC: "52:54:00:aa:bb:cc", DstMAC: "ff:ff:ff:ff:ff:ff",
+			EtherType: "rarp", ARPOpcode: "Request_Reverse", ARPSrcMAC: "52:54:00:aa:bb:cc",
+			ARPDstMAC: "52:54:00:aa:bb:cc", ARPSrcIP: "0.0.0.0", ARPDstIP: "0.0.0.0",
+		}, NWFILTER_ACTION_ACCEPT, "qemu-announce-self-rarp"},
+	}
+	for _, c := range cases {
+		verdict, err := eval.Evaluate(c.pkt)
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if verdict.Action != c.action || verdict.Filter != c.filter {
+			t.Errorf("%s: got %s by %q, expected %s by %q", c.name, verdict.Action, verdict.Filter, c.action, c.filter)
+		}
+		if verdict.Rule == nil {
+			t.Errorf("%s: no deciding rule reported", c.name)
+		}
+	}
+}
+
+func TestNWFilterEvaluateBuilderPolicy(t *testing.T) {
+	filter, err := NewNWFilterBuilder("allow-ssh", NWFILTER_CHAIN_ROOT).
+		AllowTCPIn(22).
+		AllowEstablished().
+		DropAll().
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	eval, err := NewNWFilterEvaluator(filter, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		pkt    NWFilterPacket
+		action string
+		prio   int
+	}{
+		{NWFilterPacket{Direction: NWFILTER_DIRECTION_IN, SrcIP: "192.168.1.1", DstIP: "10.0.0.5",
+			Protocol: "tcp", SrcPort: 40000, DstPort: 22, State: "NEW"}, NWFILTER_ACTION_ACCEPT, 100},
+		{NWFilterPacket{Direction: NWFILTER_DIRECTION_IN, SrcIP: "192.168.1.1", DstIP: "10.0.0.5",
+			Protocol: "tcp", SrcPort: 40000, DstPort: 80, State: "NEW"}, NWFILTER_ACTION_DROP, 1000},
+		{NWFilterPacket{Direction: NWFILTER_DIRECTION_OUT, SrcIP: "10.0.0.5", DstIP: "192.168.1.1",
+			Protocol: "tcp", SrcPort: 22, DstPort: 40000, State: "ESTABLISHED"}, NWFILTER_ACTION_ACCEPT, 110},
+		{NWFilterPacket{Direction: NWFILTER_DIRECTION_OUT, SrcIP: "10.0.0.5", DstIP: "192.168.1.1",
+			Protocol: "udp", DstPort: 53, State: "NEW"}, NWFILTER_ACTION_DROP, 1000},
+	}
+	for i, c := range cases {
+		verdict, err := eval.Evaluate(c.pkt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if verdict.Action != c.action || verdict.Rule == nil || *verdict.Rule.Priority != c.prio {
+			t.Errorf("case %d: got %s by %+v, expected %s at priority %d", i, verdict.Action, verdict.Rule, c.action, c.prio)
+		}
+	}
+}
+
+func TestNWFilterEvaluatePriorityOrder(t *testing.T) {
+	doc := `<filter name='prio' chain='root'>
+  <rule action='accept' direction='inout' priority='500'><tcp dstportstart='80'/></rule>
+  <rule action='continue' direction='inout' priority='100'><tcp dstportstart='80'/></rule>
+  <rule action='drop' direction='inout' priority='200'><tcp dstportstart='80' dstportend='90'/></rule>
+</filter>`
+	var filter NWFilterXML
+	if err := filter.Unmarshal(doc); err != nil {
+		t.Fatal(err)
+	}
+	eval, err := NewNWFilterEvaluator(&filter, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verdict, err := eval.Evaluate(NWFilterPacket{Direction: NWFILTER_DIRECTION_IN, SrcIP: "10.0.0.1",
+		DstIP: "10.0.0.2", Protocol: "tcp", DstPort: 80})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verdict.Action != NWFILTER_ACTION_DROP || *verdict.Rule.Priority != 200 {
+		t.Errorf("got %s by %+v, expected drop at priority 200", verdict.Action, verdict.Rule)
+	}
+	if len(verdict.Matched) != 2 || verdict.Matched[0].Rule.Action != NWFILTER_ACTION_CONTINUE {
+		t.Errorf("matched rules: %+v", verdict.Matched)
+	}
+}
+
+func TestNWFilterEvaluateNegatedMatch(t *testing.T) {
+	doc := `<filter name='negated' chain='ipv4'>
+  <rule action='drop' direction='out' priority='500'><ip match='no' srcipaddr='$IP'/></rule>
+</filter>`
+	var filter NWFilterXML
+	if err := filter.Unmarshal(doc); err != nil {
+		t.Fatal(err)
+	}
+	eval, err := NewNWFilterEvaluator(&filter, nil, map[string][]string{"IP": {"10.0.0.5"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for ip, action := range map[string]string{"10.0.0.5": NWFILTER_ACTION_ACCEPT, "10.0.0.9": NWFILTER_ACTION_DROP} {
+		verdict, err := eval.Evaluate(NWFilterPacket{Direction: NWFILTER_DIRECTION_OUT, SrcIP: ip, DstIP: "10.0.0.1"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if verdict.Action != action {
+			t.Errorf("%s: got %s, expected %s", ip, verdict.Action, action)
+		}
+	}
+}
+
+func TestNWFilterEvaluateIterators(t *testing.T) {
+	match := NWFilterMatch{SrcIPAddr: "$ADDR[@1]", SrcPortStart: "$PORT[@1]", DstIPAddr: "$DST", DstPortStart: "$PORT[1]"}
+	vars := map[string][]string{
+		"ADDR": {"10.0.0.1", "10.0.0.2"},
+		"PORT": {"80", "443"},
+		"DST":  {"10.1.0.1", "10.1.0.2", "10.1.0.3"},
+	}
+	out, err := expandNWFilterMatch(match, vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 6 {
+		t.Fatalf("%d instances, expected 6", len(out))
+	}
+	for _, m := range out {
+		if (m.SrcIPAddr == "10.0.0.1") != (m.SrcPortStart == "80") || m.DstPortStart != "443" {
+			t.Errorf("bad instance %+v", m)
+		}
+	}
+}
+
+func TestNWFilterEvaluateErrors(t *testing.T) {
+	resolve := NWFilterMapResolver(map[string]string{
+		"a": `<filter name='a'><filterref filter='b'/></filter>`,
+		"b": `<filter name='b'><filterref filter='a'/></filter>`,
+	})
+	a, _ := resolve("a")
+	if _, err := NewNWFilterEvaluator(a, resolve, nil); err == nil {
+		t.Error("expected error for reference loop")
+	}
+	root, _ := NWFilterMapResolver(testBuiltinNWFilters)("clean-traffic")
+	if _, err := NewNWFilterEvaluator(root, NWFilterMapResolver(testBuiltinNWFilters), nil); err == nil {
+		t.Error("expected error for unbound variables")
+	}
+	if _, err := NewNWFilterEvaluator(root, nil, nil); err == nil {
+		t.Error("expected error for missing resolver")
+	}
+}
@@ -0,0 +1,391 @@
+package libvirt
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Chains a filter can be attached to. Filters may also use a chain name
+// made of one of the protocol chains plus a suffix, e.g. "ipv4-xyz".
+const (
+	NWFILTER_CHAIN_ROOT = "root"
+	NWFILTER_CHAIN_MAC  = "mac"
+	NWFILTER_CHAIN_STP  = "stp"
+	NWFILTER_CHAIN_VLAN = "vlan"
+	NWFILTER_CHAIN_ARP  = "arp"
+	NWFILTER_CHAIN_RARP = "rarp"
+	NWFILTER_CHAIN_IPV4 = "ipv4"
+	NWFILTER_CHAIN_IPV6 = "ipv6"
+)
+
+const (
+	NWFILTER_ACTION_ACCEPT   = "accept"
+	NWFILTER_ACTION_DROP     = "drop"
+	NWFILTER_ACTION_REJECT   = "reject"
+	NWFILTER_ACTION_RETURN   = "return"
+	NWFILTER_ACTION_CONTINUE = "continue"
+)
+
+const (
+	NWFILTER_DIRECTION_IN    = "in"
+	NWFILTER_DIRECTION_OUT   = "out"
+	NWFILTER_DIRECTION_INOUT = "inout"
+)
+
+// NWFilterXML is the typed form of the <filter> document accepted by
+// NWFilterDefineXML and returned by VirNWFilter.GetXMLDesc. Rules and
+// filter references keep their document order in Entries.
+type NWFilterXML struct {
+	Name     string
+	Chain    string
+	Priority *int
+	UUID     string
+	Entries  []NWFilterEntry
+}
+
+// NWFilterEntry holds exactly one of Rule or Ref.
+type NWFilterEntry struct {
+	Rule *NWFilterRule
+	Ref  *NWFilterRef
+}
+
+type NWFilterRef struct {
+	Filter     string              `xml:"filter,attr"`
+	Parameters []NWFilterParameter `xml:"parameter"`
+}
+
+type NWFilterParameter struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// NWFilterRule carries at most one protocol match. A rule without any
+// match applies to all traffic seen by the filter's chain.
+type NWFilterRule struct {
+	Action     string         `xml:"action,attr"`
+	Direction  string         `xml:"direction,attr"`
+	Priority   *int           `xml:"priority,attr,omitempty"`
+	StateMatch string         `xml:"statematch,attr,omitempty"`
+	MAC        *NWFilterMatch `xml:"mac"`
+	VLAN       *NWFilterMatch `xml:"vlan"`
+	ARP        *NWFilterMatch `xml:"arp"`
+	RARP       *NWFilterMatch `xml:"rarp"`
+	IP         *NWFilterMatch `xml:"ip"`
+	IPv6       *NWFilterMatch `xml:"ipv6"`
+	TCP        *NWFilterMatch `xml:"tcp"`
+	UDP        *NWFilterMatch `xml:"udp"`
+	SCTP       *NWFilterMatch `xml:"sctp"`
+	ICMP       *NWFilterMatch `xml:"icmp"`
+	IGMP       *NWFilterMatch `xml:"igmp"`
+	All        *NWFilterMatch `xml:"all"`
+	TCPIPv6    *NWFilterMatch `xml:"tcp-ipv6"`
+	UDPIPv6    *NWFilterMatch `xml:"udp-ipv6"`
+	SCTPIPv6   *NWFilterMatch `xml:"sctp-ipv6"`
+	ICMPIPv6   *NWFilterMatch `xml:"icmpv6"`
+	AllIPv6    *NWFilterMatch `xml:"all-ipv6"`
+}
+
+// NWFilterMatch holds the attributes of every protocol element. Values are
+// kept as strings since any of them may reference a variable such as $IP.
+// Only the attributes valid for the enclosing element should be set.
+type NWFilterMatch struct {
+	Match          string `xml:"match,attr,omitempty"`
+	SrcMACAddr     string `xml:"srcmacaddr,attr,omitempty"`
+	SrcMACMask     string `xml:"srcmacmask,attr,omitempty"`
+	DstMACAddr     string `xml:"dstmacaddr,attr,omitempty"`
+	DstMACMask     string `xml:"dstmacmask,attr,omitempty"`
+	ProtocolID     string `xml:"protocolid,attr,omitempty"`
+	VLANID         string `xml:"vlanid,attr,omitempty"`
+	EncapProtocol  string `xml:"encap-protocol,attr,omitempty"`
+	HWType         string `xml:"hwtype,attr,omitempty"`
+	ProtocolType   string `xml:"protocoltype,attr,omitempty"`
+	Opcode         string `xml:"opcode,attr,omitempty"`
+	ARPSrcMACAddr  string `xml:"arpsrcmacaddr,attr,omitempty"`
+	ARPDstMACAddr  string `xml:"arpdstmacaddr,attr,omitempty"`
+	ARPSrcIPAddr   string `xml:"arpsrcipaddr,attr,omitempty"`
+	ARPSrcIPMask   string `xml:"arpsrcipmask,attr,omitempty"`
+	ARPDstIPAddr   string `xml:"arpdstipaddr,attr,omitempty"`
+	ARPDstIPMask   string `xml:"arpdstipmask,attr,omitempty"`
+	Gratuitous     string `xml:"gratuitous,attr,omitempty"`
+	SrcIPAddr      string `xml:"srcipaddr,attr,omitempty"`
+	SrcIPMask      string `xml:"srcipmask,attr,omitempty"`
+	DstIPAddr      string `xml:"dstipaddr,attr,omitempty"`
+	DstIPMask      string `xml:"dstipmask,attr,omitempty"`
+	SrcIPFrom      string `xml:"srcipfrom,attr,omitempty"`
+	SrcIPTo        string `xml:"srcipto,attr,omitempty"`
+	DstIPFrom      string `xml:"dstipfrom,attr,omitempty"`
+	DstIPTo        string `xml:"dstipto,attr,omitempty"`
+	Protocol       string `xml:"protocol,attr,omitempty"`
+	SrcPortStart   string `xml:"srcportstart,attr,omitempty"`
+	SrcPortEnd     string `xml:"srcportend,attr,omitempty"`
+	DstPortStart   string `xml:"dstportstart,attr,omitempty"`
+	DstPortEnd     string `xml:"dstportend,attr,omitempty"`
+	Type           string `xml:"type,attr,omitempty"`
+	Code           string `xml:"code,attr,omitempty"`
+	DSCP           string `xml:"dscp,attr,omitempty"`
+	State          string `xml:"state,attr,omitempty"`
+	Flags          string `xml:"flags,attr,omitempty"`
+	IPSet          string `xml:"ipset,attr,omitempty"`
+	IPSetFlags     string `xml:"ipsetflags,attr,omitempty"`
+	ConnLimitAbove string `xml:"connlimit-above,attr,omitempty"`
+	Comment        string `xml:"comment,attr,omitempty"`
+}
+
+// Protocol returns the element name and attributes of the rule's protocol
+// match, or "" and nil when the rule matches everything.
+func (r *NWFilterRule) Protocol() (string, *NWFilterMatch) {
+	matches := []struct {
+		name  string
+		match *NWFilterMatch
+	}{
+		{"mac", r.MAC}, {"vlan", r.VLAN}, {"arp", r.ARP}, {"rarp", r.RARP},
+		{"ip", r.IP}, {"ipv6", r.IPv6}, {"tcp", r.TCP}, {"udp", r.UDP},
+		{"sctp", r.SCTP}, {"icmp", r.ICMP}, {"igmp", r.IGMP}, {"all", r.All},
+		{"tcp-ipv6", r.TCPIPv6}, {"udp-ipv6", r.UDPIPv6},
+		{"sctp-ipv6", r.SCTPIPv6}, {"icmpv6", r.ICMPIPv6}, {"all-ipv6", r.AllIPv6},
+	}
+	for _, m := range matches {
+		if m.match != nil {
+			return m.name, m.match
+		}
+	}
+	return "", nil
+}
+
+func (f *NWFilterXML) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*f = NWFilterXML{}
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "name":
+			f.Name = attr.Value
+		case "chain":
+			f.Chain = attr.Value
+		case "priority":
+			prio, err := strconv.Atoi(attr.Value)
+			if err != nil {
+				return fmt.Errorf("invalid filter priority %q", attr.Value)
+			}
+			f.Priority = &prio
+		}
+	}
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "uuid":
+				err = d.DecodeElement(&f.UUID, &t)
+			case "rule":
+				rule := &NWFilterRule{}
+				err = d.DecodeElement(rule, &t)
+				f.Entries = append(f.Entries, NWFilterEntry{Rule: rule})
+			case "filterref":
+				ref := &NWFilterRef{}
+				err = d.DecodeElement(ref, &t)
+				f.Entries = append(f.Entries, NWFilterEntry{Ref: ref})
+			default:
+				err = d.Skip()
+			}
+			if err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+func (f NWFilterXML) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "filter"
+	start.Attr = []xml.Attr{{Name: xml.Name{Local: "name"}, Value: f.Name}}
+	if f.Chain != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "chain"}, Value: f.Chain})
+	}
+	if f.Priority != nil {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "priority"}, Value: strconv.Itoa(*f.Priority)})
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if f.UUID != "" {
+		if err := e.EncodeElement(f.UUID, xml.StartElement{Name: xml.Name{Local: "uuid"}}); err != nil {
+			return err
+		}
+	}
+	for _, entry := range f.Entries {
+		var err error
+		if entry.Rule != nil {
+			err = e.EncodeElement(entry.Rule, xml.StartElement{Name: xml.Name{Local: "rule"}})
+		} else if entry.Ref != nil {
+			err = e.EncodeElement(entry.Ref, xml.StartElement{Name: xml.Name{Local: "filterref"}})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (f *NWFilterXML) Marshal() (string, error) {
+	doc, err := xml.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(doc), nil
+}
+
+func (f *NWFilterXML) Unmarshal(doc string) error {
+	return xml.Unmarshal([]byte(doc), f)
+}
+
+// nwfilterMaxPriority is the highest, thus last evaluated, rule priority.
+const nwfilterMaxPriority = 1000
+
+// NWFilterBuilder assembles filters rule by rule. Rules added without an
+// explicit priority are numbered upwards from 100 in steps of 10 so they
+// are evaluated in the order they were added. That leaves room for 90 of
+// them below the 1000 of DropAll, the highest priority libvirt allows;
+// Build fails if more are added.
+type NWFilterBuilder struct {
+	filter   NWFilterXML
+	priority int
+	err      error
+}
+
+func NewNWFilterBuilder(name, chain string) *NWFilterBuilder {
+	return &NWFilterBuilder{filter: NWFilterXML{Name: name, Chain: chain}, priority: 100}
+}
+
+// Priority sets the filter's priority within its chain.
+func (b *NWFilterBuilder) Priority(priority int) *NWFilterBuilder {
+	b.filter.Priority = &priority
+	return b
+}
+
+// Ref includes another filter, passing it the given variables.
+func (b *NWFilterBuilder) Ref(filter string, params map[string]string) *NWFilterBuilder {
+	ref := &NWFilterRef{Filter: filter}
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		ref.Parameters = append(ref.Parameters, NWFilterParameter{Name: name, Value: params[name]})
+	}
+	b.filter.Entries = append(b.filter.Entries, NWFilterEntry{Ref: ref})
+	return b
+}
+
+// Rule appends rule, assigning the next priority if it has none.
+func (b *NWFilterBuilder) Rule(rule NWFilterRule) *NWFilterBuilder {
+	if rule.Priority == nil {
+		if b.priority >= nwfilterMaxPriority {
+			b.fail(fmt.Errorf("too many rules in filter %s for automatic priorities", b.filter.Name))
+		}
+		prio := b.priority
+		rule.Priority = &prio
+		b.priority += 10
+	}
+	b.filter.Entries = append(b.filter.Entries, NWFilterEntry{Rule: &rule})
+	return b
+}
+
+// AllowTCPIn accepts new incoming TCP connections to the given ports.
+func (b *NWFilterBuilder) AllowTCPIn(ports ...int) *NWFilterBuilder {
+	for _, port := range ports {
+		b.Rule(NWFilterRule{
+			Action:    NWFILTER_ACTION_ACCEPT,
+			Direction: NWFILTER_DIRECTION_IN,
+			TCP:       &NWFilterMatch{DstPortStart: strconv.Itoa(port), State: "NEW"},
+		})
+	}
+	return b
+}
+
+// AllowTCPOut accepts new outgoing TCP connections to the given ports.
+func (b *NWFilterBuilder) AllowTCPOut(ports ...int) *NWFilterBuilder {
+	for _, port := range ports {
+		b.Rule(NWFilterRule{
+			Action:    NWFILTER_ACTION_ACCEPT,
+			Direction: NWFILTER_DIRECTION_OUT,
+			TCP:       &NWFilterMatch{DstPortStart: strconv.Itoa(port), State: "NEW"},
+		})
+	}
+	return b
+}
+
+// AllowUDPIn accepts incoming UDP datagrams to the given ports.
+func (b *NWFilterBuilder) AllowUDPIn(ports ...int) *NWFilterBuilder {
+	for _, port := range ports {
+		b.Rule(NWFilterRule{
+			Action:    NWFILTER_ACTION_ACCEPT,
+			Direction: NWFILTER_DIRECTION_IN,
+			UDP:       &NWFilterMatch{DstPortStart: strconv.Itoa(port)},
+		})
+	}
+	return b
+}
+
+// AllowUDPOut accepts outgoing UDP datagrams to the given ports.
+func (b *NWFilterBuilder) AllowUDPOut(ports ...int) *NWFilterBuilder {
+	for _, port := range ports {
+		b.Rule(NWFilterRule{
+			Action:    NWFILTER_ACTION_ACCEPT,
+			Direction: NWFILTER_DIRECTION_OUT,
+			UDP:       &NWFilterMatch{DstPortStart: strconv.Itoa(port)},
+		})
+	}
+	return b
+}
+
+// AllowICMP accepts ICMP in both directions.
+func (b *NWFilterBuilder) AllowICMP() *NWFilterBuilder {
+	return b.Rule(NWFilterRule{
+		Action:    NWFILTER_ACTION_ACCEPT,
+		Direction: NWFILTER_DIRECTION_INOUT,
+		ICMP:      &NWFilterMatch{},
+	})
+}
+
+// AllowEstablished accepts traffic belonging to connections that are
+// already established, in both directions.
+func (b *NWFilterBuilder) AllowEstablished() *NWFilterBuilder {
+	return b.Rule(NWFilterRule{
+		Action:    NWFILTER_ACTION_ACCEPT,
+		Direction: NWFILTER_DIRECTION_INOUT,
+		All:       &NWFilterMatch{State: "ESTABLISHED,RELATED"},
+	})
+}
+
+// DropAll drops everything not accepted so far. It uses the lowest possible
+// precedence, so it stays last whatever is added after it.
+func (b *NWFilterBuilder) DropAll() *NWFilterBuilder {
+	prio := nwfilterMaxPriority
+	return b.Rule(NWFilterRule{
+		Action:    NWFILTER_ACTION_DROP,
+		Direction: NWFILTER_DIRECTION_INOUT,
+		Priority:  &prio,
+		All:       &NWFilterMatch{},
+	})
+}
+
+func (b *NWFilterBuilder) Build() (*NWFilterXML, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	filter := b.filter
+	filter.Entries = append([]NWFilterEntry(nil), b.filter.Entries...)
+	return &filter, nil
+}
+
+func (b *NWFilterBuilder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
@@ -0,0 +1,257 @@
+package libvirt
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Filters shipped with libvirt in src/nwfilter/xml.
+var testBuiltinNWFilters = map[string]string{
+	"allow-arp": `<filter name='allow-arp' chain='arp'>
+  <rule direction='inout' action='accept'/>
+</filter>`,
+	"allow-dhcp": `<filter name='allow-dhcp' chain='ipv4'>
+  <!-- accept outgoing DHCP requests -->
+  <rule action='accept' direction='out' priority='100'>
+    <ip srcipaddr='0.0.0.0' dstipaddr='255.255.255.255' protocol='udp' srcportstart='68' dstportstart='67'/>
+  </rule>
+  <!-- accept incoming DHCP responses from any DHCP server -->
+  <rule action='accept' direction='in' priority='100'>
+    <ip protocol='udp' srcportstart='67' dstportstart='68'/>
+  </rule>
+</filter>`,
+	"allow-incoming-ipv4": `<filter name='allow-incoming-ipv4' chain='ipv4'>
+  <rule direction='in' action='accept'/>
+</filter>`,
+	"allow-ipv4": `<filter name='allow-ipv4' chain='ipv4'>
+  <rule direction='inout' action='accept'/>
+</filter>`,
+	"clean-traffic": `<filter name='clean-traffic' chain='root'>
+  <!-- An example of a traffic filter enforcing clean traffic
+       from a VM by
+     - preventing MAC spoofing -->
+  <filterref filter='no-mac-spoofing'/>
+  <!-- preventing IP spoofing on outgoing -->
+  <filterref filter='no-ip-spoofing'/>
+  <!-- preventing ARP spoofing/poisoning -->
+  <rule direction='out' action='accept' priority='-650'>
+    <mac protocolid='ipv4'/>
+  </rule>
+  <filterref filter='allow-incoming-ipv4'/>
+  <filterref filter='no-arp-spoofing'/>
+  <rule direction='inout' action='accept' priority='-500'>
+    <mac protocolid='arp'/>
+  </rule>
+  <filterref filter='no-other-arp-traffic'/>
+  <filterref filter='no-other-l2-traffic'/>
+  <!-- allow qemu to send a self-announce upon migration end -->
+  <filterref filter='qemu-announce-self'/>
+</filter>`,
+	"no-arp-ip-spoofing": `<filter name='no-arp-ip-spoofing' chain='arp-ip' priority='-510'>
+  <!-- no arp spoofing -->
+  <!-- drop if ipaddr does not belong to guest -->
+  <rule action='return' direction='out' priority='400'>
+    <arp match='yes' arpsrcipaddr='$IP'/>
+  </rule>
+  <!-- drop everything else -->
+  <rule action='drop' direction='out' priority='1000'/>
+</filter>`,
+	"no-arp-mac-spoofing": `<filter name='no-arp-mac-spoofing' chain='arp-mac' priority='-520'>
+  <rule action='return' direction='out' priority='350'>
+    <arp match='yes' arpsrcmacaddr='$MAC'/>
+  </rule>
+  <!-- drop everything else -->
+  <rule action='drop' direction='out' priority='1000'/>
+</filter>`,
+	"no-arp-spoofing": `<filter name='no-arp-spoofing' chain='root'>
+  <filterref filter='no-arp-mac-spoofing'/>
+  <filterref filter='no-arp-ip-spoofing'/>
+</filter>`,
+	"no-ip-multicast": `<filter name='no-ip-multicast' chain='ipv4'>
+  <!-- drop if destination IP address is in the 224.0.0.0/4 subnet -->
+  <rule action='drop' direction='out'>
+    <ip dstipaddr='224.0.0.0' dstipmask='4'/>
+  </rule>
+  <!-- not doing anything with receiving side ... -->
+</filter>`,
+	"no-ip-spoofing": `<filter name='no-ip-spoofing' chain='ipv4-ip' priority='-710'>
+  <!-- allow UDP sent from 0.0.0.0:68 (DHCP); DHCP requests are
+       filtered by the allow-dhcp filter -->
+  <rule action='return' direction='out' priority='100'>
+    <ip srcipaddr='0.0.0.0' protocol='udp' srcportstart='68' srcportend='68'/>
+  </rule>
+  <!-- allow all known IP addresses -->
+  <rule action='return' direction='out' priority='500'>
+    <ip srcipaddr='$IP'/>
+  </rule>
+  <!-- drop everything else -->
+  <rule action='drop' direction='out' priority='1000'/>
+</filter>`,
+	"no-mac-broadcast": `<filter name='no-mac-broadcast' chain='ipv4'>
+  <!-- drop if destination mac is bcast mac addr. -->
+  <rule action='drop' direction='out'>
+    <mac dstmacaddr='ff:ff:ff:ff:ff:ff'/>
+  </rule>
+  <!-- not doing anything with receiving side ... -->
+</filter>`,
+	"no-mac-spoofing": `<filter name='no-mac-spoofing' chain='mac' priority='-800'>
+  <!-- return packets with VM's MAC address as source address -->
+  <rule direction='out' action='return'>
+    <mac srcmacaddr='$MAC'/>
+  </rule>
+  <!-- drop everything else -->
+  <rule direction='out' action='drop'>
+    <mac/>
+  </rule>
+</filter>`,
+	"no-other-arp-traffic": `<filter name='no-other-arp-traffic' chain='arp'>
+  <rule action='drop' direction='inout' priority='1000'/>
+</filter>`,
+	"no-other-l2-traffic": `<filter name='no-other-l2-traffic'>
+  <!-- drop all other l2 traffic than for which rules have been
+       written for; i.e., drop all other than arp and ipv4 traffic -->
+  <rule action='drop' direction='inout' priority='1000'/>
+</filter>`,
+	"no-other-rarp-traffic": `<filter name='no-other-rarp-traffic' chain='rarp'>
+  <rule action='drop' direction='inout' priority='1000'/>
+</filter>`,
+	"qemu-announce-self": `<filter name='qemu-announce-self' chain='root'>
+  <!-- as of 4.2.0 qemu sends out ARP requests instead of RARP -->
+  <rule action='accept' direction='out'>
+    <mac protocolid='0x835'/>
+  </rule>
+  <filterref filter='qemu-announce-self-rarp'/>
+</filter>`,
+	"qemu-announce-self-rarp": `<filter name='qemu-announce-self-rarp' chain='rarp' priority='-400'>
+  <rule action='accept' direction='out' priority='500'>
+    <rarp srcmacaddr='$MAC' dstmacaddr='ff:ff:ff:ff:ff:ff' opcode='Request_Reverse' arpsrcmacaddr='$MAC' arpdstmacaddr='$MAC' arpsrcipaddr='0.0.0.0' arpdstipaddr='0.0.0.0'/>
+  </rule>
+  <rule action='drop' direction='out' priority='500'/>
+</filter>`,
+}
+
+func TestNWFilterXMLRoundTripBuiltin(t *testing.T) {
+	for name, doc := range testBuiltinNWFilters {
+		var filter NWFilterXML
+		if err := filter.Unmarshal(doc); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if filter.Name != name {
+			t.Errorf("%s: name == %q", name, filter.Name)
+		}
+		out, err := filter.Marshal()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		var again NWFilterXML
+		if err := again.Unmarshal(out); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(filter, again) {
+			t.Errorf("%s: round trip mismatch:\n%s\n%s", name, doc, out)
+		}
+	}
+}
+
+func TestNWFilterXMLEntryOrder(t *testing.T) {
+	var filter NWFilterXML
+	if err := filter.Unmarshal(testBuiltinNWFilters["clean-traffic"]); err != nil {
+		t.Fatal(err)
+	}
+	order := []string{"no-mac-spoofing", "no-ip-spoofing", "mac", "allow-incoming-ipv4",
+		"no-arp-spoofing", "mac", "no-other-arp-traffic", "no-other-l2-traffic", "qemu-announce-self"}
+	if len(filter.Entries) != len(order) {
+		t.Fatalf("%d entries, expected %d", len(filter.Entries), len(order))
+	}
+	for i, entry := range filter.Entries {
+		var got string
+		if entry.Ref != nil {
+			got = entry.Ref.Filter
+		} else {
+			got, _ = entry.Rule.Protocol()
+		}
+		if got != order[i] {
+			t.Errorf("entry %d == %q, expected %q", i, got, order[i])
+		}
+	}
+	rule := filter.Entries[2].Rule
+	if rule.Action != NWFILTER_ACTION_ACCEPT || rule.Direction != NWFILTER_DIRECTION_OUT ||
+		*rule.Priority != -650 || rule.MAC.ProtocolID != "ipv4" {
+		t.Errorf("rule not parsed: %+v", rule)
+	}
+}
+
+func TestNWFilterXMLRuleAttributes(t *testing.T) {
+	var filter NWFilterXML
+	if err := filter.Unmarshal(testBuiltinNWFilters["no-arp-ip-spoofing"]); err != nil {
+		t.Fatal(err)
+	}
+	if filter.Chain != "arp-ip" || filter.Priority == nil || *filter.Priority != -510 {
+		t.Errorf("filter attributes not parsed: %+v", filter)
+	}
+	proto, match := filter.Entries[0].Rule.Protocol()
+	if proto != "arp" || match.Match != "yes" || match.ARPSrcIPAddr != "$IP" {
+		t.Errorf("arp match not parsed: %s %+v", proto, match)
+	}
+	if proto, match := filter.Entries[1].Rule.Protocol(); proto != "" || match != nil {
+		t.Errorf("catch-all rule has protocol %q", proto)
+	}
+}
+
+func TestNWFilterBuilder(t *testing.T) {
+	filter, err := NewNWFilterBuilder("allow-ssh", NWFILTER_CHAIN_ROOT).
+		Ref("clean-traffic", map[string]string{"IP": "10.0.0.5", "MAC": "52:54:00:00:00:01"}).
+		AllowTCPIn(22).
+		AllowEstablished().
+		DropAll().
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filter.Entries) != 4 {
+		t.Fatalf("%d entries, expected 4", len(filter.Entries))
+	}
+	ref := filter.Entries[0].Ref
+	if ref.Filter != "clean-traffic" || len(ref.Parameters) != 2 || ref.Parameters[0].Name != "IP" {
+		t.Errorf("filterref not built: %+v", ref)
+	}
+	ssh := filter.Entries[1].Rule
+	if ssh.Direction != NWFILTER_DIRECTION_IN || ssh.TCP.DstPortStart != "22" || *ssh.Priority != 100 {
+		t.Errorf("ssh rule not built: %+v", ssh)
+	}
+	if established := filter.Entries[2].Rule; *established.Priority != 110 || established.All.State != "ESTABLISHED,RELATED" {
+		t.Errorf("established rule not built: %+v", established)
+	}
+	if drop := filter.Entries[3].Rule; drop.Action != NWFILTER_ACTION_DROP || *drop.Priority != 1000 {
+		t.Errorf("drop rule not built: %+v", drop)
+	}
+	doc, err := filter.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var again NWFilterXML
+	if err := again.Unmarshal(doc); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*filter, again) {
+		t.Errorf("round trip mismatch:\n%s", doc)
+	}
+}
+
+func TestNWFilterBuilderPriorityOverflow(t *testing.T) {
+	ports := make([]int, 90)
+	for i := range ports {
+		ports[i] = 1000 + i
+	}
+	b := NewNWFilterBuilder("many-ports", NWFILTER_CHAIN_ROOT).AllowTCPIn(ports...)
+	filter, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := filter.Entries[len(filter.Entries)-1].Rule; *last.Priority != 990 {
+		t.Errorf("last rule has priority %d, expected 990", *last.Priority)
+	}
+	if _, err := b.AllowICMP().DropAll().Build(); err == nil {
+		t.Error("expected error once automatic priorities reach the one of DropAll")
+	}
+}
@@ -0,0 +1,38 @@
+package libvirt
+
+import (
+	"encoding/xml"
+)
+
+// SecretXML is the typed form of the <secret> document accepted by
+// SecretDefineXML and returned by VirSecret.GetXMLDesc.
+type SecretXML struct {
+	XMLName     xml.Name     `xml:"secret"`
+	Ephemeral   string       `xml:"ephemeral,attr,omitempty"`
+	Private     string       `xml:"private,attr,omitempty"`
+	UUID        string       `xml:"uuid,omitempty"`
+	Description string       `xml:"description,omitempty"`
+	Usage       *SecretUsage `xml:"usage"`
+}
+
+// SecretUsage ties the secret to the object using it. Type is "volume"
+// (set Volume), "iscsi" (set Target), or "ceph", "tls" or "vtpm" (set
+// Name).
+type SecretUsage struct {
+	Type   string `xml:"type,attr"`
+	Volume string `xml:"volume,omitempty"`
+	Name   string `xml:"name,omitempty"`
+	Target string `xml:"target,omitempty"`
+}
+
+func (s *SecretXML) Marshal() (string, error) {
+	doc, err := xml.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(doc), nil
+}
+
+func (s *SecretXML) Unmarshal(doc string) error {
+	return xml.Unmarshal([]byte(doc), s)
+}
@@ -0,0 +1,51 @@
+package libvirt
+
+import (
+	"testing"
+)
+
+func TestSecretXMLRoundTrip(t *testing.T) {
+	docs := []string{
+		`<secret ephemeral="no" private="yes">
+  <uuid>8e0ebf10-1a3a-4e07-9c4f-0b4bd1f6a6a2</uuid>
+  <description>Super secret name of my first puppy</description>
+  <usage type="volume">
+    <volume>/var/lib/libvirt/images/puppyname.img</volume>
+  </usage>
+</secret>`,
+		`<secret ephemeral="no" private="yes">
+  <description>CEPH passphrase example</description>
+  <usage type="ceph">
+    <name>ceph_example</name>
+  </usage>
+</secret>`,
+		`<secret ephemeral="no" private="yes">
+  <description>iSCSI passphrase for the example iSCSI target</description>
+  <usage type="iscsi">
+    <target>libvirtiscsi</target>
+  </usage>
+</secret>`,
+		`<secret ephemeral="no" private="yes">
+  <description>sample tls secret</description>
+  <usage type="tls">
+    <name>TLS_example</name>
+  </usage>
+</secret>`,
+	}
+	for _, doc := range docs {
+		var secret SecretXML
+		if err := secret.Unmarshal(doc); err != nil {
+			t.Fatal(err)
+		}
+		if secret.Usage == nil || secret.Usage.Volume+secret.Usage.Name+secret.Usage.Target == "" {
+			t.Errorf("usage not parsed: %+v", secret.Usage)
+		}
+		out, err := secret.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out != doc {
+			t.Errorf("round trip mismatch:\n%s\n%s", doc, out)
+		}
+	}
+}
@@ -9,6 +9,8 @@ package libvirt
 import "C"
 
 import (
+	"reflect"
+	"sort"
 	"unsafe"
 )
 
@@ -49,6 +51,196 @@ func (d *VirDomain) CreateSnapshotXML(xml string, flags uint32) (VirDomainSnapsh
 	return VirDomainSnapshot{ptr: result}, nil
 }
 
+func (s *VirDomainSnapshot) GetName() (string, error) {
+	name := C.virDomainSnapshotGetName(s.ptr)
+	if name == nil {
+		return "", GetLastError()
+	}
+	return C.GoString(name), nil
+}
+
+func (s *VirDomainSnapshot) GetXMLDesc(flags uint32) (string, error) {
+	result := C.virDomainSnapshotGetXMLDesc(s.ptr, C.uint(flags))
+	if result == nil {
+		return "", GetLastError()
+	}
+	xml := C.GoString(result)
+	C.free(unsafe.Pointer(result))
+	return xml, nil
+}
+
+func (s *VirDomainSnapshot) GetParent(flags uint32) (VirDomainSnapshot, error) {
+	ptr := C.virDomainSnapshotGetParent(s.ptr, C.uint(flags))
+	if ptr == nil {
+		return VirDomainSnapshot{}, GetLastError()
+	}
+	return VirDomainSnapshot{ptr: ptr}, nil
+}
+
+func (s *VirDomainSnapshot) ListAllChildren(flags uint32) ([]VirDomainSnapshot, error) {
+	var cList *C.virDomainSnapshotPtr
+	numSnaps := C.virDomainSnapshotListAllChildren(s.ptr, (**C.virDomainSnapshotPtr)(&cList), C.uint(flags))
+	if numSnaps == -1 {
+		return nil, GetLastError()
+	}
+	return snapshotsFromCList(cList, int(numSnaps)), nil
+}
+
+func (s *VirDomainSnapshot) IsCurrent(flags uint32) (bool, error) {
+	result := C.virDomainSnapshotIsCurrent(s.ptr, C.uint(flags))
+	if result == -1 {
+		return false, GetLastError()
+	}
+	if result == 1 {
+		return true, nil
+	}
+	return false, nil
+}
+
+func (s *VirDomainSnapshot) HasMetadata(flags uint32) (bool, error) {
+	result := C.virDomainSnapshotHasMetadata(s.ptr, C.uint(flags))
+	if result == -1 {
+		return false, GetLastError()
+	}
+	if result == 1 {
+		return true, nil
+	}
+	return false, nil
+}
+
+func snapshotsFromCList(cList *C.virDomainSnapshotPtr, numSnaps int) []VirDomainSnapshot {
+	hdr := reflect.SliceHeader{
+		Data: uintptr(unsafe.Pointer(cList)),
+		Len:  numSnaps,
+		Cap:  numSnaps,
+	}
+	var snaps []VirDomainSnapshot
+	slice := *(*[]C.virDomainSnapshotPtr)(unsafe.Pointer(&hdr))
+	for _, ptr := range slice {
+		snaps = append(snaps, VirDomainSnapshot{ptr})
+	}
+	C.free(unsafe.Pointer(cList))
+	return snaps
+}
+
+func (d *VirDomain) ListAllSnapshots(flags uint32) ([]VirDomainSnapshot, error) {
+	var cList *C.virDomainSnapshotPtr
+	numSnaps := C.virDomainListAllSnapshots(d.ptr, (**C.virDomainSnapshotPtr)(&cList), C.uint(flags))
+	if numSnaps == -1 {
+		return nil, GetLastError()
+	}
+	return snapshotsFromCList(cList, int(numSnaps)), nil
+}
+
+func (d *VirDomain) SnapshotLookupByName(name string, flags uint32) (VirDomainSnapshot, error) {
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+	ptr := C.virDomainSnapshotLookupByName(d.ptr, cName, C.uint(flags))
+	if ptr == nil {
+		return VirDomainSnapshot{}, GetLastError()
+	}
+	return VirDomainSnapshot{ptr: ptr}, nil
+}
+
+func (d *VirDomain) SnapshotCurrent(flags uint32) (VirDomainSnapshot, error) {
+	ptr := C.virDomainSnapshotCurrent(d.ptr, C.uint(flags))
+	if ptr == nil {
+		return VirDomainSnapshot{}, GetLastError()
+	}
+	return VirDomainSnapshot{ptr: ptr}, nil
+}
+
+func (d *VirDomain) HasCurrentSnapshot(flags uint32) (bool, error) {
+	result := C.virDomainHasCurrentSnapshot(d.ptr, C.uint(flags))
+	if result == -1 {
+		return false, GetLastError()
+	}
+	if result == 1 {
+		return true, nil
+	}
+	return false, nil
+}
+
+// DomainSnapshotNode is one snapshot in the tree built by SnapshotTree.
+type DomainSnapshotNode struct {
+	Snapshot VirDomainSnapshot
+	XML      DomainSnapshotXML
+	Current  bool
+	Parent   *DomainSnapshotNode
+	Children []*DomainSnapshotNode
+}
+
+// Free releases the snapshot objects of the node and all its descendants.
+func (n *DomainSnapshotNode) Free() {
+	for _, child := range n.Children {
+		child.Free()
+	}
+	n.Snapshot.Free()
+}
+
+// SnapshotTree returns the root snapshots of the domain with their
+// descendants linked below them. Siblings are ordered by creation time.
+// Call Free on every root once done with the tree.
+func (d *VirDomain) SnapshotTree() ([]*DomainSnapshotNode, error) {
+	snaps, err := d.ListAllSnapshots(0)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[string]*DomainSnapshotNode, len(snaps))
+	all := make([]*DomainSnapshotNode, 0, len(snaps))
+	for i, snap := range snaps {
+		node := &DomainSnapshotNode{Snapshot: snap}
+		doc, err := snap.GetXMLDesc(0)
+		if err == nil {
+			err = node.XML.Unmarshal(doc)
+		}
+		if err == nil {
+			node.Current, err = snap.IsCurrent(0)
+		}
+		if err != nil {
+			for _, s := range snaps[i:] {
+				s.Free()
+			}
+			for _, n := range all {
+				n.Snapshot.Free()
+			}
+			return nil, err
+		}
+		nodes[node.XML.Name] = node
+		all = append(all, node)
+	}
+
+	var roots []*DomainSnapshotNode
+	for _, node := range all {
+		if node.XML.Parent != nil {
+			if parent, ok := nodes[node.XML.Parent.Name]; ok {
+				node.Parent = parent
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+
+	sort.Sort(snapshotNodesByCreation(roots))
+	for _, node := range all {
+		sort.Sort(snapshotNodesByCreation(node.Children))
+	}
+	return roots, nil
+}
+
+type snapshotNodesByCreation []*DomainSnapshotNode
+
+func (s snapshotNodesByCreation) Len() int      { return len(s) }
+func (s snapshotNodesByCreation) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s snapshotNodesByCreation) Less(i, j int) bool {
+	if s[i].XML.CreationTime != s[j].XML.CreationTime {
+		return s[i].XML.CreationTime < s[j].XML.CreationTime
+	}
+	return s[i].XML.Name < s[j].XML.Name
+}
+
 func (d *VirDomain) Save(destFile string) error {
 	cPath := C.CString(destFile)
 	defer C.free(unsafe.Pointer(cPath))
@@ -93,3 +285,64 @@ func (conn VirConnection) RestoreFlags(srcFile, xmlConf string, flags uint32) er
 	}
 	return nil
 }
+
+// ManagedSave saves the state of a running domain to a file managed by
+// libvirt and stops it. The next Create restores the domain from that
+// file. With VIR_DOMAIN_SAVE_RUNNING or VIR_DOMAIN_SAVE_PAUSED the state
+// the domain is restored in can be forced.
+func (d *VirDomain) ManagedSave(flags uint32) error {
+	result := C.virDomainManagedSave(d.ptr, C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
+
+// HasManagedSaveImage tells whether the domain has a managed save image,
+// which the next Create restores it from.
+func (d *VirDomain) HasManagedSaveImage(flags uint32) (bool, error) {
+	result := C.virDomainHasManagedSaveImage(d.ptr, C.uint(flags))
+	if result == -1 {
+		return false, GetLastError()
+	}
+	return result == 1, nil
+}
+
+// ManagedSaveRemove discards the managed save image of the domain, so that
+// it boots afresh on the next Create.
+func (d *VirDomain) ManagedSaveRemove(flags uint32) error {
+	result := C.virDomainManagedSaveRemove(d.ptr, C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
+
+// SaveImageGetXMLDesc returns the domain XML stored in the save image
+// file, as written by Save or SaveFlags.
+func (conn VirConnection) SaveImageGetXMLDesc(file string, flags uint32) (string, error) {
+	cFile := C.CString(file)
+	defer C.free(unsafe.Pointer(cFile))
+	result := C.virDomainSaveImageGetXMLDesc(conn.ptr, cFile, C.uint(flags))
+	if result == nil {
+		return "", GetLastError()
+	}
+	defer C.free(unsafe.Pointer(result))
+	return C.GoString(result), nil
+}
+
+// SaveImageDefineXML replaces the domain XML stored in the save image
+// file. Only changes that keep the guest ABI, such as disk paths, are
+// allowed. VIR_DOMAIN_SAVE_RUNNING or VIR_DOMAIN_SAVE_PAUSED also change
+// the state the domain is restored in.
+func (conn VirConnection) SaveImageDefineXML(file string, xml string, flags uint32) error {
+	cFile := C.CString(file)
+	defer C.free(unsafe.Pointer(cFile))
+	cXml := C.CString(xml)
+	defer C.free(unsafe.Pointer(cXml))
+	result := C.virDomainSaveImageDefineXML(conn.ptr, cFile, cXml, C.uint(flags))
+	if result == -1 {
+		return GetLastError()
+	}
+	return nil
+}
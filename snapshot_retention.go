@@ -0,0 +1,283 @@
+package libvirt
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	SNAPSHOT_RETENTION_CREATE = "create"
+	SNAPSHOT_RETENTION_DELETE = "delete"
+)
+
+// snapshotRetentionLayout is the timestamp suffix of the names of managed
+// snapshots. Timestamps are in UTC.
+const snapshotRetentionLayout = "20060102-150405"
+
+// SnapshotRetentionPolicy keeps rotating snapshots of a domain. Snapshots it
+// manages are named Prefix, a dash and their UTC creation timestamp; any
+// other snapshot of the domain is left alone.
+//
+// For each period the newest snapshot of each of the last Hourly hours,
+// Daily days and Weekly ISO weeks that have one is kept, the periods being
+// evaluated independently and a snapshot kept as long as one of them keeps
+// it. A new snapshot is due when none exists for the current hour, or for
+// the current day or week when Hourly, respectively Hourly and Daily, are
+// zero.
+type SnapshotRetentionPolicy struct {
+	Prefix      string
+	Hourly      int
+	Daily       int
+	Weekly      int
+	CreateFlags uint32 // Passed to CreateSnapshotXML
+	DryRun      bool   // Only report the actions Apply would take
+}
+
+// SnapshotRetentionAction is a step taken, or planned in dry-run mode, by
+// Apply.
+type SnapshotRetentionAction struct {
+	Op    string // SNAPSHOT_RETENTION_CREATE or SNAPSHOT_RETENTION_DELETE
+	Name  string
+	Flags uint32 // VIR_DOMAIN_SNAPSHOT_DELETE_CHILDREN when deleting a whole subtree
+}
+
+func (a SnapshotRetentionAction) String() string {
+	if a.Op == SNAPSHOT_RETENTION_DELETE && a.Flags&VIR_DOMAIN_SNAPSHOT_DELETE_CHILDREN != 0 {
+		return a.Op + " " + a.Name + " and its children"
+	}
+	return a.Op + " " + a.Name
+}
+
+// snapshotName returns the name of the managed snapshot taken at t.
+func (p *SnapshotRetentionPolicy) snapshotName(t time.Time) string {
+	return p.Prefix + "-" + t.UTC().Format(snapshotRetentionLayout)
+}
+
+// snapshotTime returns the creation time encoded in the name of a managed
+// snapshot, and false for snapshots not managed by the policy.
+func (p *SnapshotRetentionPolicy) snapshotTime(name string) (time.Time, bool) {
+	if !strings.HasPrefix(name, p.Prefix+"-") {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation(snapshotRetentionLayout, name[len(p.Prefix)+1:], time.UTC)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+type snapshotRetentionPeriod struct {
+	keep   int
+	bucket func(t time.Time) string
+}
+
+func (p *SnapshotRetentionPolicy) periods() []snapshotRetentionPeriod {
+	return []snapshotRetentionPeriod{
+		{p.Hourly, func(t time.Time) string { return t.Format("2006010215") }},
+		{p.Daily, func(t time.Time) string { return t.Format("20060102") }},
+		{p.Weekly, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-%02d", year, week)
+		}},
+	}
+}
+
+func (p *SnapshotRetentionPolicy) validate() error {
+	if p.Prefix == "" {
+		return errors.New("snapshot retention policy needs a prefix")
+	}
+	if p.Hourly < 0 || p.Daily < 0 || p.Weekly < 0 {
+		return errors.New("snapshot retention counts must not be negative")
+	}
+	if p.Hourly == 0 && p.Daily == 0 && p.Weekly == 0 {
+		return errors.New("snapshot retention policy keeps no snapshots")
+	}
+	return nil
+}
+
+type snapshotRetentionEntry struct {
+	node *DomainSnapshotNode
+	time time.Time
+}
+
+// snapshotRetentionEntriesByTime sorts the newest entries first.
+type snapshotRetentionEntriesByTime []snapshotRetentionEntry
+
+func (s snapshotRetentionEntriesByTime) Len() int           { return len(s) }
+func (s snapshotRetentionEntriesByTime) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s snapshotRetentionEntriesByTime) Less(i, j int) bool { return s[i].time.After(s[j].time) }
+
+// plan computes the actions needed to bring the snapshot tree rooted at
+// roots in line with the policy at time now. Bucket boundaries follow the
+// location of now.
+func (p *SnapshotRetentionPolicy) plan(roots []*DomainSnapshotNode, now time.Time) ([]SnapshotRetentionAction, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+
+	var managed []snapshotRetentionEntry
+	var current *DomainSnapshotNode
+	var walk func(nodes []*DomainSnapshotNode)
+	walk = func(nodes []*DomainSnapshotNode) {
+		for _, node := range nodes {
+			if t, ok := p.snapshotTime(node.XML.Name); ok {
+				managed = append(managed, snapshotRetentionEntry{node, t.In(now.Location())})
+			}
+			if node.Current {
+				current = node
+			}
+			walk(node.Children)
+		}
+	}
+	walk(roots)
+
+	var actions []SnapshotRetentionAction
+	var parentOfNew *DomainSnapshotNode
+	periods := p.periods()
+	finest := periods[0]
+	for _, period := range periods {
+		if period.keep > 0 {
+			finest = period
+			break
+		}
+	}
+	due := true
+	for _, entry := range managed {
+		if finest.bucket(entry.time) == finest.bucket(now) {
+			due = false
+			break
+		}
+	}
+	if due {
+		// The new snapshot is the newest in its bucket, so it is always
+		// kept. It becomes a child of the current snapshot, which must
+		// then not be deleted along with its children.
+		name := p.snapshotName(now)
+		managed = append(managed, snapshotRetentionEntry{&DomainSnapshotNode{XML: DomainSnapshotXML{Name: name}}, now})
+		actions = append(actions, SnapshotRetentionAction{Op: SNAPSHOT_RETENTION_CREATE, Name: name})
+		parentOfNew = current
+	}
+
+	sort.Sort(snapshotRetentionEntriesByTime(managed))
+	keep := make(map[*DomainSnapshotNode]bool)
+	for _, period := range periods {
+		seen := make(map[string]bool)
+		for _, entry := range managed {
+			if len(seen) == period.keep {
+				break
+			}
+			bucket := period.bucket(entry.time)
+			if !seen[bucket] {
+				seen[bucket] = true
+				keep[entry.node] = true
+			}
+		}
+	}
+	remove := make(map[*DomainSnapshotNode]bool)
+	for _, entry := range managed {
+		if !keep[entry.node] {
+			remove[entry.node] = true
+		}
+	}
+
+	var removeAll func(node *DomainSnapshotNode) bool
+	removeAll = func(node *DomainSnapshotNode) bool {
+		if !remove[node] || node == parentOfNew {
+			return false
+		}
+		for _, child := range node.Children {
+			if !removeAll(child) {
+				return false
+			}
+		}
+		return true
+	}
+	var collect func(nodes []*DomainSnapshotNode)
+	collect = func(nodes []*DomainSnapshotNode) {
+		for _, node := range nodes {
+			if !remove[node] {
+				collect(node.Children)
+				continue
+			}
+			if len(node.Children) > 0 && removeAll(node) {
+				actions = append(actions, SnapshotRetentionAction{
+					Op:    SNAPSHOT_RETENTION_DELETE,
+					Name:  node.XML.Name,
+					Flags: VIR_DOMAIN_SNAPSHOT_DELETE_CHILDREN,
+				})
+				continue
+			}
+			actions = append(actions, SnapshotRetentionAction{Op: SNAPSHOT_RETENTION_DELETE, Name: node.XML.Name})
+			collect(node.Children)
+		}
+	}
+	collect(roots)
+	return actions, nil
+}
+
+// Apply creates the snapshot of the domain that is due at the time given
+// by clock, if any, then deletes the managed snapshots falling outside the
+// policy. A managed snapshot whose descendants all go is deleted with
+// VIR_DOMAIN_SNAPSHOT_DELETE_CHILDREN; otherwise its children are kept and
+// reparented by libvirt. clock defaults to time.Now.
+//
+// The actions taken are returned, up to and including a failed one. In
+// dry-run mode they are only planned.
+func (p *SnapshotRetentionPolicy) Apply(d *VirDomain, clock func() time.Time) ([]SnapshotRetentionAction, error) {
+	if clock == nil {
+		clock = time.Now
+	}
+	now := clock()
+
+	roots, err := d.SnapshotTree()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for _, root := range roots {
+			root.Free()
+		}
+	}()
+
+	actions, err := p.plan(roots, now)
+	if err != nil || p.DryRun {
+		return actions, err
+	}
+
+	nodes := make(map[string]*DomainSnapshotNode)
+	var index func(children []*DomainSnapshotNode)
+	index = func(children []*DomainSnapshotNode) {
+		for _, node := range children {
+			nodes[node.XML.Name] = node
+			index(node.Children)
+		}
+	}
+	index(roots)
+
+	for i, action := range actions {
+		switch action.Op {
+		case SNAPSHOT_RETENTION_CREATE:
+			doc, err := (&DomainSnapshotXML{
+				Name:        action.Name,
+				Description: "Created by snapshot retention policy " + p.Prefix,
+			}).Marshal()
+			if err != nil {
+				return actions[:i+1], err
+			}
+			snap, err := d.CreateSnapshotXML(doc, p.CreateFlags)
+			if err != nil {
+				return actions[:i+1], err
+			}
+			snap.Free()
+		case SNAPSHOT_RETENTION_DELETE:
+			node := nodes[action.Name]
+			if err := node.Snapshot.Delete(action.Flags); err != nil {
+				return actions[:i+1], err
+			}
+		}
+	}
+	return actions, nil
+}
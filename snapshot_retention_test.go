@@ -0,0 +1,165 @@
+package libvirt
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func buildTestSnapshotNode(parent *DomainSnapshotNode, name string) *DomainSnapshotNode {
+	node := &DomainSnapshotNode{XML: DomainSnapshotXML{Name: name}, Parent: parent}
+	if parent != nil {
+		parent.Children = append(parent.Children, node)
+	}
+	return node
+}
+
+func TestSnapshotRetentionPlan(t *testing.T) {
+	root := buildTestSnapshotNode(nil, "auto-20261017-100000")
+	hourly := buildTestSnapshotNode(root, "auto-20261017-110000")
+	buildTestSnapshotNode(hourly, "auto-20261017-120000").Current = true
+	branch := buildTestSnapshotNode(root, "auto-20261017-103000")
+	buildTestSnapshotNode(branch, "auto-20261017-104000")
+	manual := buildTestSnapshotNode(nil, "manual")
+	buildTestSnapshotNode(manual, "auto-20261015-080000")
+
+	policy := SnapshotRetentionPolicy{Prefix: "auto", Hourly: 2, Daily: 2}
+	now := time.Date(2026, 10, 17, 12, 20, 0, 0, time.UTC)
+	actions, err := policy.plan([]*DomainSnapshotNode{root, manual}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []SnapshotRetentionAction{
+		{Op: SNAPSHOT_RETENTION_DELETE, Name: "auto-20261017-100000"},
+		{Op: SNAPSHOT_RETENTION_DELETE, Name: "auto-20261017-103000", Flags: VIR_DOMAIN_SNAPSHOT_DELETE_CHILDREN},
+	}
+	if !reflect.DeepEqual(actions, expected) {
+		t.Errorf("got %v, expected %v", actions, expected)
+	}
+}
+
+func TestSnapshotRetentionPlanCreate(t *testing.T) {
+	root := buildTestSnapshotNode(nil, "auto-20261017-100000")
+	buildTestSnapshotNode(root, "auto-20261017-110000").Current = true
+
+	policy := SnapshotRetentionPolicy{Prefix: "auto", Hourly: 1}
+	now := time.Date(2026, 10, 17, 12, 5, 0, 0, time.UTC)
+	actions, err := policy.plan([]*DomainSnapshotNode{root}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []SnapshotRetentionAction{
+		{Op: SNAPSHOT_RETENTION_CREATE, Name: "auto-20261017-120500"},
+		{Op: SNAPSHOT_RETENTION_DELETE, Name: "auto-20261017-100000"},
+		{Op: SNAPSHOT_RETENTION_DELETE, Name: "auto-20261017-110000"},
+	}
+	if !reflect.DeepEqual(actions, expected) {
+		t.Errorf("got %v, expected %v", actions, expected)
+	}
+}
+
+func TestSnapshotRetentionPlanWeekly(t *testing.T) {
+	var roots []*DomainSnapshotNode
+	day := time.Date(2026, 9, 1, 3, 0, 0, 0, time.UTC)
+	policy := SnapshotRetentionPolicy{Prefix: "nightly", Daily: 2, Weekly: 3}
+	for i := 0; i < 21; i++ {
+		roots = append(roots, buildTestSnapshotNode(nil, policy.snapshotName(day.AddDate(0, 0, i))))
+	}
+	now := day.AddDate(0, 0, 20).Add(time.Hour)
+	actions, err := policy.plan(roots, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept := make(map[string]bool)
+	for _, root := range roots {
+		kept[root.XML.Name] = true
+	}
+	for _, action := range actions {
+		if action.Op != SNAPSHOT_RETENTION_DELETE {
+			t.Errorf("unexpected action %v", action)
+		}
+		delete(kept, action.Name)
+	}
+	var names []string
+	for name := range kept {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	// The 21st is a Monday, so the weekly buckets also keep the Sundays
+	// ending the two previous ISO weeks.
+	expected := []string{"nightly-20260913-030000", "nightly-20260920-030000", "nightly-20260921-030000"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("kept %v, expected %v", names, expected)
+	}
+}
+
+func TestSnapshotRetentionPolicyErrors(t *testing.T) {
+	for _, policy := range []SnapshotRetentionPolicy{
+		{Hourly: 1},
+		{Prefix: "auto"},
+		{Prefix: "auto", Daily: -1, Weekly: 1},
+	} {
+		if _, err := policy.plan(nil, time.Now()); err == nil {
+			t.Errorf("expected error for %+v", policy)
+		}
+	}
+}
+
+func TestSnapshotRetentionApply(t *testing.T) {
+	dom, conn := buildTestDomain()
+	defer func() {
+		dom.UndefineFlags(VIR_DOMAIN_UNDEFINE_SNAPSHOTS_METADATA)
+		dom.Free()
+		if res, _ := conn.CloseConnection(); res != 0 {
+			t.Errorf("CloseConnection() == %d, expected 0", res)
+		}
+	}()
+
+	manual := buildTestSnapshot(t, dom, "manual")
+	defer manual.Free()
+
+	policy := SnapshotRetentionPolicy{Prefix: "auto", Hourly: 2}
+	start := time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		clock := func() time.Time { return start.Add(time.Duration(i) * time.Hour) }
+		if _, err := policy.Apply(&dom, clock); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	listNames := func() []string {
+		snaps, err := dom.ListAllSnapshots(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, snap := range snaps {
+			name, _ := snap.GetName()
+			names = append(names, name)
+			snap.Free()
+		}
+		sort.Strings(names)
+		return names
+	}
+	expected := []string{"auto-20261017-110000", "auto-20261017-120000", "manual"}
+	if names := listNames(); !reflect.DeepEqual(names, expected) {
+		t.Fatalf("snapshots %v, expected %v", names, expected)
+	}
+
+	policy.DryRun = true
+	actions, err := policy.Apply(&dom, func() time.Time { return start.Add(3 * time.Hour) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	planned := []SnapshotRetentionAction{
+		{Op: SNAPSHOT_RETENTION_CREATE, Name: "auto-20261017-130000"},
+		{Op: SNAPSHOT_RETENTION_DELETE, Name: "auto-20261017-110000"},
+	}
+	if !reflect.DeepEqual(actions, planned) {
+		t.Errorf("planned %v, expected %v", actions, planned)
+	}
+	if names := listNames(); !reflect.DeepEqual(names, expected) {
+		t.Errorf("dry run changed snapshots to %v", names)
+	}
+}
@@ -0,0 +1,182 @@
+package libvirt
+
+import (
+	"testing"
+)
+
+func buildTestSnapshot(t *testing.T, dom VirDomain, name string) VirDomainSnapshot {
+	ss, err := dom.CreateSnapshotXML(`<domainsnapshot><name>`+name+`</name></domainsnapshot>`, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ss
+}
+
+func TestDomainSnapshotLookup(t *testing.T) {
+	dom, conn := buildTestDomain()
+	defer func() {
+		dom.UndefineFlags(VIR_DOMAIN_UNDEFINE_SNAPSHOTS_METADATA)
+		dom.Free()
+		if res, _ := conn.CloseConnection(); res != 0 {
+			t.Errorf("CloseConnection() == %d, expected 0", res)
+		}
+	}()
+
+	if has, err := dom.HasCurrentSnapshot(0); err != nil || has {
+		t.Fatalf("HasCurrentSnapshot() == %v, %v, expected false", has, err)
+	}
+
+	s1 := buildTestSnapshot(t, dom, "s1")
+	defer s1.Free()
+	s2 := buildTestSnapshot(t, dom, "s2")
+	defer s2.Free()
+
+	if has, err := dom.HasCurrentSnapshot(0); err != nil || !has {
+		t.Fatalf("HasCurrentSnapshot() == %v, %v, expected true", has, err)
+	}
+	current, err := dom.SnapshotCurrent(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer current.Free()
+	if name, err := current.GetName(); err != nil || name != "s2" {
+		t.Errorf("SnapshotCurrent() == %q, %v, expected s2", name, err)
+	}
+	if isCurrent, err := s1.IsCurrent(0); err != nil || isCurrent {
+		t.Errorf("s1.IsCurrent() == %v, %v, expected false", isCurrent, err)
+	}
+	if hasMetadata, err := s2.HasMetadata(0); err != nil || !hasMetadata {
+		t.Errorf("s2.HasMetadata() == %v, %v, expected true", hasMetadata, err)
+	}
+
+	lookup, err := dom.SnapshotLookupByName("s2", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lookup.Free()
+	parent, err := lookup.GetParent(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer parent.Free()
+	if name, _ := parent.GetName(); name != "s1" {
+		t.Errorf("GetParent() == %q, expected s1", name)
+	}
+
+	doc, err := lookup.GetXMLDesc(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var snapXML DomainSnapshotXML
+	if err := snapXML.Unmarshal(doc); err != nil {
+		t.Fatal(err)
+	}
+	if snapXML.Name != "s2" || snapXML.Parent == nil || snapXML.Parent.Name != "s1" {
+		t.Errorf("unexpected snapshot XML: %s", doc)
+	}
+
+	snaps, err := dom.ListAllSnapshots(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snaps) != 2 {
+		t.Errorf("ListAllSnapshots() returned %d snapshots, expected 2", len(snaps))
+	}
+	for _, snap := range snaps {
+		snap.Free()
+	}
+	children, err := s1.ListAllChildren(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(children) != 1 {
+		t.Errorf("ListAllChildren() returned %d snapshots, expected 1", len(children))
+	}
+	for _, child := range children {
+		child.Free()
+	}
+}
+
+func TestDomainSnapshotTree(t *testing.T) {
+	dom, conn := buildTestDomain()
+	defer func() {
+		dom.UndefineFlags(VIR_DOMAIN_UNDEFINE_SNAPSHOTS_METADATA)
+		dom.Free()
+		if res, _ := conn.CloseConnection(); res != 0 {
+			t.Errorf("CloseConnection() == %d, expected 0", res)
+		}
+	}()
+
+	s1 := buildTestSnapshot(t, dom, "s1")
+	defer s1.Free()
+	s2 := buildTestSnapshot(t, dom, "s2")
+	defer s2.Free()
+	if err := s1.RevertToSnapshot(0); err != nil {
+		t.Fatal(err)
+	}
+	s3 := buildTestSnapshot(t, dom, "s3")
+	defer s3.Free()
+	s4 := buildTestSnapshot(t, dom, "s4")
+	defer s4.Free()
+
+	roots, err := dom.SnapshotTree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		for _, root := range roots {
+			root.Free()
+		}
+	}()
+
+	if len(roots) != 1 || roots[0].XML.Name != "s1" {
+		t.Fatalf("unexpected roots %+v", roots)
+	}
+	children := roots[0].Children
+	if len(children) != 2 || children[0].XML.Name != "s2" || children[1].XML.Name != "s3" {
+		t.Fatalf("unexpected children of s1 %+v", children)
+	}
+	if len(children[1].Children) != 1 {
+		t.Fatalf("unexpected children of s3 %+v", children[1].Children)
+	}
+	leaf := children[1].Children[0]
+	if leaf.XML.Name != "s4" || !leaf.Current || leaf.Parent != children[1] {
+		t.Errorf("unexpected leaf %+v", leaf)
+	}
+	if roots[0].Current || children[0].Current {
+		t.Error("only s4 should be current")
+	}
+}
+
+func TestDomainManagedSave(t *testing.T) {
+	dom, conn := buildTestDomain()
+	defer func() {
+		dom.Destroy()
+		dom.ManagedSaveRemove(0)
+		dom.Undefine()
+		dom.Free()
+		if res, _ := conn.CloseConnection(); res != 0 {
+			t.Errorf("CloseConnection() == %d, expected 0", res)
+		}
+	}()
+	if err := dom.Create(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := dom.ManagedSave(0); err != nil {
+		t.Fatal(err)
+	}
+	if has, err := dom.HasManagedSaveImage(0); err != nil || !has {
+		t.Fatalf("HasManagedSaveImage() == %v, %v, expected true", has, err)
+	}
+	if active, _ := dom.IsActive(); active {
+		t.Error("domain still active after ManagedSave()")
+	}
+
+	if err := dom.ManagedSaveRemove(0); err != nil {
+		t.Fatal(err)
+	}
+	if has, err := dom.HasManagedSaveImage(0); err != nil || has {
+		t.Errorf("HasManagedSaveImage() == %v, %v, expected false", has, err)
+	}
+}
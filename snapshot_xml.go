@@ -0,0 +1,81 @@
+package libvirt
+
+import (
+	"encoding/xml"
+)
+
+// DomainSnapshotXML is the typed form of the <domainsnapshot> document
+// accepted by VirDomain.CreateSnapshotXML.
+type DomainSnapshotXML struct {
+	XMLName      xml.Name                 `xml:"domainsnapshot"`
+	Name         string                   `xml:"name,omitempty"`
+	Description  string                   `xml:"description,omitempty"`
+	State        string                   `xml:"state,omitempty"`
+	CreationTime int64                    `xml:"creationTime,omitempty"`
+	Parent       *DomainSnapshotParent    `xml:"parent"`
+	Memory       *DomainSnapshotMemory    `xml:"memory"`
+	Disks        *DomainSnapshotDisks     `xml:"disks"`
+	Active       string                   `xml:"active,omitempty"`
+	Domain       *DomainSnapshotDomainXML `xml:"domain"`
+}
+
+type DomainSnapshotParent struct {
+	Name string `xml:"name"`
+}
+
+// DomainSnapshotMemory selects how guest memory is captured: "no",
+// "internal" or "external", the latter saving it to File.
+type DomainSnapshotMemory struct {
+	Snapshot string `xml:"snapshot,attr"`
+	File     string `xml:"file,attr,omitempty"`
+}
+
+type DomainSnapshotDisks struct {
+	Disks []DomainSnapshotDisk `xml:"disk"`
+}
+
+// DomainSnapshotDisk sets the snapshot mode of one disk, named by target
+// device or source path: "no", "internal" or "external".
+type DomainSnapshotDisk struct {
+	Name     string                    `xml:"name,attr"`
+	Snapshot string                    `xml:"snapshot,attr,omitempty"`
+	Type     string                    `xml:"type,attr,omitempty"`
+	Driver   *DomainSnapshotDiskDriver `xml:"driver"`
+	Source   *DomainSnapshotDiskSource `xml:"source"`
+}
+
+type DomainSnapshotDiskDriver struct {
+	Type string `xml:"type,attr"`
+}
+
+type DomainSnapshotDiskSource struct {
+	File     string                         `xml:"file,attr,omitempty"`
+	Dev      string                         `xml:"dev,attr,omitempty"`
+	Protocol string                         `xml:"protocol,attr,omitempty"`
+	Name     string                         `xml:"name,attr,omitempty"`
+	Hosts    []DomainSnapshotDiskSourceHost `xml:"host"`
+}
+
+type DomainSnapshotDiskSourceHost struct {
+	Name string `xml:"name,attr"`
+	Port string `xml:"port,attr,omitempty"`
+}
+
+// DomainSnapshotDomainXML keeps the domain definition recorded with the
+// snapshot verbatim.
+type DomainSnapshotDomainXML struct {
+	Type     string `xml:"type,attr,omitempty"`
+	InnerXML string `xml:",innerxml"`
+}
+
+func (s *DomainSnapshotXML) Marshal() (string, error) {
+	doc, err := xml.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(doc), nil
+}
+
+func (s *DomainSnapshotXML) Unmarshal(doc string) error {
+	return xml.Unmarshal([]byte(doc), s)
+}
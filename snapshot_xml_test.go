@@ -0,0 +1,82 @@
+package libvirt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testDomainSnapshotFullXML = `<domainsnapshot>
+  <name>os-updates</name>
+  <description>Snapshot of OS install and updates</description>
+  <state>running</state>
+  <creationTime>1270477159</creationTime>
+  <parent>
+    <name>bare-os-install</name>
+  </parent>
+  <memory snapshot="external" file="/var/lib/libvirt/images/os-updates.mem"></memory>
+  <disks>
+    <disk name="vda" snapshot="external" type="file">
+      <driver type="qcow2"></driver>
+      <source file="/var/lib/libvirt/images/os-updates.qcow2"></source>
+    </disk>
+    <disk name="vdb" snapshot="no"></disk>
+    <disk name="vdc" snapshot="external" type="network">
+      <source protocol="rbd" name="pool/os-updates">
+        <host name="ceph-mon" port="6789"></host>
+      </source>
+    </disk>
+  </disks>
+  <domain type="test">
+    <name>test</name>
+    <memory>8388608</memory>
+  </domain>
+</domainsnapshot>`
+
+func TestDomainSnapshotXMLRoundTrip(t *testing.T) {
+	var snap DomainSnapshotXML
+	if err := snap.Unmarshal(testDomainSnapshotFullXML); err != nil {
+		t.Fatal(err)
+	}
+	if snap.Parent.Name != "bare-os-install" || snap.CreationTime != 1270477159 || snap.Memory.Snapshot != "external" {
+		t.Errorf("snapshot not parsed: %+v", snap)
+	}
+	disks := snap.Disks.Disks
+	if len(disks) != 3 || disks[0].Source.File == "" || disks[1].Snapshot != "no" || disks[2].Source.Hosts[0].Port != "6789" {
+		t.Errorf("disks not parsed: %+v", disks)
+	}
+	if snap.Domain.Type != "test" || !strings.Contains(snap.Domain.InnerXML, "<name>test</name>") {
+		t.Errorf("domain not kept: %+v", snap.Domain)
+	}
+	out, err := snap.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var again DomainSnapshotXML
+	if err := again.Unmarshal(out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(snap, again) {
+		t.Errorf("round trip mismatch:\n%s\n%s", testDomainSnapshotFullXML, out)
+	}
+}
+
+func TestDomainSnapshotXMLCreate(t *testing.T) {
+	dom, conn := buildTestDomain()
+	defer func() {
+		dom.Free()
+		if res, _ := conn.CloseConnection(); res != 0 {
+			t.Errorf("CloseConnection() == %d, expected 0", res)
+		}
+	}()
+	snap := DomainSnapshotXML{Name: "typed", Description: "created from DomainSnapshotXML"}
+	doc, err := snap.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss, err := dom.CreateSnapshotXML(doc, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ss.Free()
+}
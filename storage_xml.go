@@ -0,0 +1,252 @@
+package libvirt
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// StorageSize is a scaled integer such as <capacity unit='GiB'>10</capacity>.
+// An empty unit means bytes.
+type StorageSize struct {
+	Unit  string `xml:"unit,attr,omitempty"`
+	Value uint64 `xml:",chardata"`
+}
+
+// Units accepted by libvirt for scaled integers. Single letters and the
+// "iB" suffix are powers of 1024, the "B" suffix powers of 1000.
+var storageSizeScale = map[string]uint64{
+	"":      1,
+	"b":     1,
+	"bytes": 1,
+	"kb":    1000,
+	"k":     1 << 10,
+	"kib":   1 << 10,
+	"mb":    1000 * 1000,
+	"m":     1 << 20,
+	"mib":   1 << 20,
+	"gb":    1000 * 1000 * 1000,
+	"g":     1 << 30,
+	"gib":   1 << 30,
+	"tb":    1000 * 1000 * 1000 * 1000,
+	"t":     1 << 40,
+	"tib":   1 << 40,
+	"pb":    1000 * 1000 * 1000 * 1000 * 1000,
+	"p":     1 << 50,
+	"pib":   1 << 50,
+	"eb":    1000 * 1000 * 1000 * 1000 * 1000 * 1000,
+	"e":     1 << 60,
+	"eib":   1 << 60,
+}
+
+// NewStorageSize returns a size expressed in bytes.
+func NewStorageSize(bytes uint64) *StorageSize {
+	return &StorageSize{Unit: "bytes", Value: bytes}
+}
+
+// ParseStorageSize accepts values such as "10G", "512 MiB" or "1000000".
+func ParseStorageSize(s string) (*StorageSize, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool { return !unicode.IsDigit(r) })
+	if i == -1 {
+		i = len(s)
+	}
+	value, err := strconv.ParseUint(s[:i], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid size %q", s)
+	}
+	size := &StorageSize{Unit: strings.TrimSpace(s[i:]), Value: value}
+	if _, err := size.Bytes(); err != nil {
+		return nil, err
+	}
+	return size, nil
+}
+
+// Bytes returns the size in bytes, failing on unknown units or overflow.
+func (s StorageSize) Bytes() (uint64, error) {
+	scale, ok := storageSizeScale[strings.ToLower(s.Unit)]
+	if !ok {
+		return 0, fmt.Errorf("unknown size unit %q", s.Unit)
+	}
+	if s.Value != 0 && scale > ^uint64(0)/s.Value {
+		return 0, fmt.Errorf("size %d%s overflows", s.Value, s.Unit)
+	}
+	return s.Value * scale, nil
+}
+
+// Pool types understood by <pool type='...'>.
+const (
+	STORAGE_POOL_TYPE_DIR      = "dir"
+	STORAGE_POOL_TYPE_FS       = "fs"
+	STORAGE_POOL_TYPE_NETFS    = "netfs"
+	STORAGE_POOL_TYPE_LOGICAL  = "logical"
+	STORAGE_POOL_TYPE_DISK     = "disk"
+	STORAGE_POOL_TYPE_ISCSI    = "iscsi"
+	STORAGE_POOL_TYPE_SCSI     = "scsi"
+	STORAGE_POOL_TYPE_MPATH    = "mpath"
+	STORAGE_POOL_TYPE_RBD      = "rbd"
+	STORAGE_POOL_TYPE_SHEEPDOG = "sheepdog"
+	STORAGE_POOL_TYPE_GLUSTER  = "gluster"
+	STORAGE_POOL_TYPE_ZFS      = "zfs"
+)
+
+// StoragePoolXML is the typed form of the <pool> document accepted by
+// StoragePoolDefineXML and returned by VirStoragePool.GetXMLDesc.
+type StoragePoolXML struct {
+	XMLName    xml.Name           `xml:"pool"`
+	Type       string             `xml:"type,attr"`
+	Name       string             `xml:"name"`
+	UUID       string             `xml:"uuid,omitempty"`
+	Capacity   *StorageSize       `xml:"capacity"`
+	Allocation *StorageSize       `xml:"allocation"`
+	Available  *StorageSize       `xml:"available"`
+	Source     *StoragePoolSource `xml:"source"`
+	Target     *StoragePoolTarget `xml:"target"`
+}
+
+type StoragePoolSource struct {
+	Hosts     []StoragePoolSourceHost   `xml:"host"`
+	Devices   []StoragePoolSourceDevice `xml:"device"`
+	Dir       *StoragePoolSourceDir     `xml:"dir"`
+	Adapter   *StoragePoolSourceAdapter `xml:"adapter"`
+	Name      string                    `xml:"name,omitempty"`
+	Format    *StorageFormat            `xml:"format"`
+	Auth      *StoragePoolSourceAuth    `xml:"auth"`
+	Initiator *StoragePoolInitiator     `xml:"initiator"`
+	Vendor    *StoragePoolSourceName    `xml:"vendor"`
+	Product   *StoragePoolSourceName    `xml:"product"`
+}
+
+type StoragePoolSourceHost struct {
+	Name string `xml:"name,attr"`
+	Port string `xml:"port,attr,omitempty"`
+}
+
+// StoragePoolSourceDevice is a block device path, or the target IQN for
+// iscsi pools.
+type StoragePoolSourceDevice struct {
+	Path          string `xml:"path,attr"`
+	PartSeparator string `xml:"part_separator,attr,omitempty"`
+}
+
+type StoragePoolSourceDir struct {
+	Path string `xml:"path,attr"`
+}
+
+type StoragePoolSourceAdapter struct {
+	Type    string `xml:"type,attr,omitempty"`
+	Name    string `xml:"name,attr,omitempty"`
+	Parent  string `xml:"parent,attr,omitempty"`
+	Managed string `xml:"managed,attr,omitempty"`
+	WWNN    string `xml:"wwnn,attr,omitempty"`
+	WWPN    string `xml:"wwpn,attr,omitempty"`
+}
+
+type StoragePoolSourceAuth struct {
+	Type     string                       `xml:"type,attr"`
+	Username string                       `xml:"username,attr"`
+	Secret   *StoragePoolSourceAuthSecret `xml:"secret"`
+}
+
+type StoragePoolSourceAuthSecret struct {
+	Usage string `xml:"usage,attr,omitempty"`
+	UUID  string `xml:"uuid,attr,omitempty"`
+}
+
+type StoragePoolInitiator struct {
+	IQN StoragePoolSourceName `xml:"iqn"`
+}
+
+type StoragePoolSourceName struct {
+	Name string `xml:"name,attr"`
+}
+
+type StoragePoolTarget struct {
+	Path        string              `xml:"path,omitempty"`
+	Permissions *StoragePermissions `xml:"permissions"`
+}
+
+type StorageFormat struct {
+	Type string `xml:"type,attr"`
+}
+
+// StoragePermissions keeps owner and group as numeric ids and mode as an
+// octal string, e.g. "0744", exactly as they appear in the XML.
+type StoragePermissions struct {
+	Owner string `xml:"owner,omitempty"`
+	Group string `xml:"group,omitempty"`
+	Mode  string `xml:"mode,omitempty"`
+	Label string `xml:"label,omitempty"`
+}
+
+// StorageVolumeXML is the typed form of the <volume> document accepted by
+// StorageVolCreateXML / StorageVolCreateXMLFrom and returned by
+// VirStorageVol.GetXMLDesc.
+type StorageVolumeXML struct {
+	XMLName      xml.Name                   `xml:"volume"`
+	Type         string                     `xml:"type,attr,omitempty"`
+	Name         string                     `xml:"name"`
+	Key          string                     `xml:"key,omitempty"`
+	Capacity     *StorageSize               `xml:"capacity"`
+	Allocation   *StorageSize               `xml:"allocation"`
+	Physical     *StorageSize               `xml:"physical"`
+	Target       *StorageVolumeTarget       `xml:"target"`
+	BackingStore *StorageVolumeBackingStore `xml:"backingStore"`
+}
+
+type StorageVolumeTarget struct {
+	Path        string                 `xml:"path,omitempty"`
+	Format      *StorageFormat         `xml:"format"`
+	Permissions *StoragePermissions    `xml:"permissions"`
+	Compat      string                 `xml:"compat,omitempty"`
+	NoCOW       *struct{}              `xml:"nocow"`
+	Features    *StorageVolumeFeatures `xml:"features"`
+	Encryption  *StorageEncryption     `xml:"encryption"`
+}
+
+type StorageVolumeFeatures struct {
+	LazyRefcounts *struct{} `xml:"lazy_refcounts"`
+}
+
+type StorageVolumeBackingStore struct {
+	Path        string              `xml:"path"`
+	Format      *StorageFormat      `xml:"format"`
+	Permissions *StoragePermissions `xml:"permissions"`
+}
+
+type StorageEncryption struct {
+	Format  string                    `xml:"format,attr"`
+	Secrets []StorageEncryptionSecret `xml:"secret"`
+}
+
+type StorageEncryptionSecret struct {
+	Type  string `xml:"type,attr"`
+	UUID  string `xml:"uuid,attr,omitempty"`
+	Usage string `xml:"usage,attr,omitempty"`
+}
+
+func (p *StoragePoolXML) Marshal() (string, error) {
+	doc, err := xml.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(doc), nil
+}
+
+func (p *StoragePoolXML) Unmarshal(doc string) error {
+	return xml.Unmarshal([]byte(doc), p)
+}
+
+func (v *StorageVolumeXML) Marshal() (string, error) {
+	doc, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(doc), nil
+}
+
+func (v *StorageVolumeXML) Unmarshal(doc string) error {
+	return xml.Unmarshal([]byte(doc), v)
+}
@@ -0,0 +1,291 @@
+package libvirt
+
+import (
+	"reflect"
+	"testing"
+)
+
+var testStoragePoolXMLs = []string{
+	`<pool type="dir">
+  <name>virtimages</name>
+  <uuid>3e3fce45-4f53-4fa7-bb32-11f34168b82b</uuid>
+  <capacity unit="bytes">4306780815</capacity>
+  <allocation unit="bytes">237457858</allocation>
+  <available unit="bytes">4069322956</available>
+  <target>
+    <path>/var/lib/virt/images</path>
+    <permissions>
+      <owner>107</owner>
+      <group>107</group>
+      <mode>0744</mode>
+      <label>virt_image_t</label>
+    </permissions>
+  </target>
+</pool>`,
+	`<pool type="fs">
+  <name>fspool</name>
+  <source>
+    <device path="/dev/sdb1"></device>
+    <format type="ext4"></format>
+  </source>
+  <target>
+    <path>/mnt/fspool</path>
+  </target>
+</pool>`,
+	`<pool type="netfs">
+  <name>nfsimages</name>
+  <source>
+    <host name="nfs.example.com"></host>
+    <dir path="/var/lib/virt/images"></dir>
+    <format type="nfs"></format>
+  </source>
+  <target>
+    <path>/var/lib/virt/images</path>
+  </target>
+</pool>`,
+	`<pool type="logical">
+  <name>HostVG</name>
+  <source>
+    <device path="/dev/sda1"></device>
+    <device path="/dev/sdb1"></device>
+    <name>HostVG</name>
+    <format type="lvm2"></format>
+  </source>
+  <target>
+    <path>/dev/HostVG</path>
+  </target>
+</pool>`,
+	`<pool type="disk">
+  <name>sda</name>
+  <source>
+    <device path="/dev/sda" part_separator="no"></device>
+    <format type="gpt"></format>
+  </source>
+  <target>
+    <path>/dev</path>
+  </target>
+</pool>`,
+	`<pool type="iscsi">
+  <name>virtimages</name>
+  <source>
+    <host name="iscsi.example.com" port="3260"></host>
+    <device path="iqn.2013-06.com.example:iscsi-pool"></device>
+    <auth type="chap" username="myname">
+      <secret usage="mycluster_myname"></secret>
+    </auth>
+    <initiator>
+      <iqn name="iqn.2013-06.com.example:iscsi-initiator"></iqn>
+    </initiator>
+  </source>
+  <target>
+    <path>/dev/disk/by-path</path>
+  </target>
+</pool>`,
+	`<pool type="rbd">
+  <name>myrbdpool</name>
+  <source>
+    <host name="1.2.3.4" port="6789"></host>
+    <host name="my.ceph.monitor"></host>
+    <name>rbdpool</name>
+    <auth type="ceph" username="admin">
+      <secret uuid="2ec115d7-3a88-3ceb-bc12-0ac909a6fd87"></secret>
+    </auth>
+  </source>
+</pool>`,
+	`<pool type="gluster">
+  <name>myglusterpool</name>
+  <source>
+    <host name="localhost"></host>
+    <dir path="/"></dir>
+    <name>volname</name>
+  </source>
+</pool>`,
+}
+
+const testStorageVolumeFullXML = `<volume type="file">
+  <name>sparse.qcow2</name>
+  <key>/var/lib/xen/images/sparse.qcow2</key>
+  <capacity unit="T">1</capacity>
+  <allocation unit="bytes">0</allocation>
+  <target>
+    <path>/var/lib/xen/images/sparse.qcow2</path>
+    <format type="qcow2"></format>
+    <permissions>
+      <owner>107</owner>
+      <group>107</group>
+      <mode>0744</mode>
+      <label>virt_image_t</label>
+    </permissions>
+    <compat>1.1</compat>
+    <features>
+      <lazy_refcounts></lazy_refcounts>
+    </features>
+    <encryption format="luks">
+      <secret type="passphrase" uuid="f52a81b2-424e-490c-823d-6bd4235bc572"></secret>
+    </encryption>
+  </target>
+  <backingStore>
+    <path>/var/lib/virt/images/master.img</path>
+    <format type="raw"></format>
+    <permissions>
+      <owner>107</owner>
+      <group>107</group>
+      <mode>0744</mode>
+    </permissions>
+  </backingStore>
+</volume>`
+
+func TestStoragePoolXMLRoundTrip(t *testing.T) {
+	for _, doc := range testStoragePoolXMLs {
+		var def StoragePoolXML
+		if err := def.Unmarshal(doc); err != nil {
+			t.Fatal(err)
+		}
+		out, err := def.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out != doc {
+			t.Errorf("round trip mismatch:\n%s\n%s", doc, out)
+		}
+	}
+}
+
+func TestStoragePoolXMLSources(t *testing.T) {
+	var iscsi StoragePoolXML
+	if err := iscsi.Unmarshal(testStoragePoolXMLs[5]); err != nil {
+		t.Fatal(err)
+	}
+	src := iscsi.Source
+	if src.Hosts[0].Port != "3260" || src.Auth.Secret.Usage != "mycluster_myname" ||
+		src.Initiator.IQN.Name != "iqn.2013-06.com.example:iscsi-initiator" {
+		t.Errorf("iscsi source not parsed: %+v", src)
+	}
+	var dir StoragePoolXML
+	if err := dir.Unmarshal(testStoragePoolXMLs[0]); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := dir.Capacity.Bytes(); n != 4306780815 {
+		t.Errorf("capacity == %d, expected 4306780815", n)
+	}
+	if dir.Target.Permissions.Mode != "0744" {
+		t.Errorf("permissions not parsed: %+v", dir.Target.Permissions)
+	}
+}
+
+func TestStorageVolumeXMLRoundTrip(t *testing.T) {
+	var def StorageVolumeXML
+	if err := def.Unmarshal(testStorageVolumeFullXML); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := def.Capacity.Bytes(); n != 1<<40 {
+		t.Errorf("capacity == %d, expected %d", n, uint64(1<<40))
+	}
+	if def.Target.Encryption.Secrets[0].Type != "passphrase" || def.BackingStore.Format.Type != "raw" {
+		t.Errorf("volume not parsed: %+v", def)
+	}
+	if def.Target.Features == nil || def.Target.Features.LazyRefcounts == nil {
+		t.Error("lazy_refcounts feature not parsed")
+	}
+	out, err := def.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var again StorageVolumeXML
+	if err := again.Unmarshal(out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(def, again) {
+		t.Errorf("round trip mismatch:\n%s\n%s", testStorageVolumeFullXML, out)
+	}
+}
+
+func TestStorageSizeBytes(t *testing.T) {
+	cases := map[string]uint64{
+		"1024":    1024,
+		"10 b":    10,
+		"1k":      1024,
+		"1KB":     1000,
+		"1KiB":    1024,
+		"10M":     10 << 20,
+		"10 MB":   10 * 1000 * 1000,
+		"2GiB":    2 << 30,
+		"1T":      1 << 40,
+		"1 bytes": 1,
+	}
+	for in, expected := range cases {
+		size, err := ParseStorageSize(in)
+		if err != nil {
+			t.Errorf("%q: %v", in, err)
+			continue
+		}
+		if n, _ := size.Bytes(); n != expected {
+			t.Errorf("%q == %d, expected %d", in, n, expected)
+		}
+	}
+	for _, in := range []string{"", "G", "10 parsecs", "20EiB"} {
+		if _, err := ParseStorageSize(in); err == nil {
+			t.Errorf("%q: expected error", in)
+		}
+	}
+}
+
+func TestStorageXMLDefine(t *testing.T) {
+	conn := buildTestConnection()
+	defer func() {
+		if res, _ := conn.CloseConnection(); res != 0 {
+			t.Errorf("CloseConnection() == %d, expected 0", res)
+		}
+	}()
+	poolDef := StoragePoolXML{
+		Type:   STORAGE_POOL_TYPE_DIR,
+		Name:   "typed-pool",
+		Target: &StoragePoolTarget{Path: "/typed-pool"},
+	}
+	doc, err := poolDef.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool, err := conn.StoragePoolDefineXML(doc, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		pool.Undefine()
+		pool.Free()
+	}()
+	if err := pool.Create(0); err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Destroy()
+
+	size, _ := ParseStorageSize("10MiB")
+	volDef := StorageVolumeXML{
+		Name:     "typed-vol",
+		Capacity: size,
+		Target:   &StorageVolumeTarget{Path: "/typed-pool/typed-vol"},
+	}
+	doc, err = volDef.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	vol, err := pool.StorageVolCreateXML(doc, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		vol.Delete(VIR_STORAGE_VOL_DELETE_NORMAL)
+		vol.Free()
+	}()
+	desc, err := vol.GetXMLDesc(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got StorageVolumeXML
+	if err := got.Unmarshal(desc); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := got.Capacity.Bytes(); n != 10<<20 {
+		t.Errorf("capacity == %d, expected %d", n, 10<<20)
+	}
+}
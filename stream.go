@@ -5,10 +5,12 @@ package libvirt
 #include <libvirt/libvirt.h>
 #include <libvirt/virterror.h>
 #include <stdlib.h>
+#include "go_libvirt.h"
 */
 import "C"
 import (
 	"io"
+	"sync"
 	"unsafe"
 )
 
@@ -77,3 +79,184 @@ func (v *VirStream) Write(p []byte) (int, error) {
 
 	return int(n), nil
 }
+
+// eventStream implements io.ReadWriteCloser over a non-blocking stream,
+// waiting for stream events when it would block.
+type eventStream struct {
+	stream   *VirStream
+	readable chan struct{}
+	writable chan struct{}
+	done     chan struct{}
+
+	lock   sync.Mutex // Guards the fields below and the use of stream
+	events int        // VIR_STREAM_EVENT_* currently waited for
+	eof    bool
+	closed bool
+}
+
+// openEventStream creates a non-blocking stream on the connection of the
+// domain, hands it to open, which connects it with some virDomainOpen* API,
+// and watches it. The stream is freed if open fails.
+func openEventStream(d *VirDomain, open func(st C.virStreamPtr) C.int) (*eventStream, error) {
+	conn := VirConnection{ptr: C.virDomainGetConnect(d.ptr)}
+	stream, err := NewVirStream(&conn, VIR_STREAM_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+	if open(stream.ptr) == -1 {
+		err := GetLastError()
+		stream.Free()
+		return nil, err
+	}
+	c := &eventStream{
+		stream:   stream,
+		readable: make(chan struct{}, 1),
+		writable: make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		events:   VIR_STREAM_EVENT_READABLE,
+	}
+	if err := c.watch(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// watch registers for the events of the opened stream. The stream is
+// aborted and freed on error.
+func (c *eventStream) watch() error {
+	goCallbackId := registerCallbackId(c)
+	if C.virStreamEventAddCallback_cgo(c.stream.ptr, C.int(c.events), C.long(goCallbackId)) == -1 {
+		err := GetLastError()
+		freeCallbackId(goCallbackId)
+		c.stream.Abort()
+		c.stream.Free()
+		return err
+	}
+	return nil
+}
+
+//export streamEventCallback
+func streamEventCallback(st C.virStreamPtr, events int, goCallbackId int) {
+	ctx := getCallbackId(goCallbackId)
+	switch cctx := ctx.(type) {
+	case *eventStream:
+		cctx.handleEvents(events)
+	default:
+		panic("Inappropriate callback type called")
+	}
+}
+
+func notifyStream(c chan struct{}) {
+	select {
+	case c <- struct{}{}:
+	default:
+	}
+}
+
+// handleEvents wakes up the pending Read or Write and stops watching the
+// events that fired, since the stream would otherwise keep reporting them
+// until it is drained. Errors and hangups wake up both so that they see
+// the failure.
+func (c *eventStream) handleEvents(events int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.closed {
+		return
+	}
+	if events&(VIR_STREAM_EVENT_READABLE|VIR_STREAM_EVENT_ERROR|VIR_STREAM_EVENT_HANGUP) != 0 {
+		c.events &^= VIR_STREAM_EVENT_READABLE
+		notifyStream(c.readable)
+	}
+	if events&(VIR_STREAM_EVENT_WRITABLE|VIR_STREAM_EVENT_ERROR|VIR_STREAM_EVENT_HANGUP) != 0 {
+		c.events &^= VIR_STREAM_EVENT_WRITABLE
+		notifyStream(c.writable)
+	}
+	C.virStreamEventUpdateCallback(c.stream.ptr, C.int(c.events))
+}
+
+// wait watches event and blocks until it fires or the stream is closed.
+// It is called with the lock held and returns with it released.
+func (c *eventStream) wait(event int, ready chan struct{}) {
+	c.events |= event
+	C.virStreamEventUpdateCallback(c.stream.ptr, C.int(c.events))
+	c.lock.Unlock()
+	select {
+	case <-ready:
+	case <-c.done:
+	}
+}
+
+func (c *eventStream) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	for {
+		c.lock.Lock()
+		if c.closed {
+			c.lock.Unlock()
+			return 0, io.ErrClosedPipe
+		}
+		n := C.virStreamRecv(c.stream.ptr, (*C.char)(unsafe.Pointer(&p[0])), C.size_t(len(p)))
+		switch {
+		case n == -2:
+			c.wait(VIR_STREAM_EVENT_READABLE, c.readable)
+			continue
+		case n < 0:
+			err := GetLastError()
+			c.lock.Unlock()
+			return 0, err
+		case n == 0:
+			c.eof = true
+			c.lock.Unlock()
+			return 0, io.EOF
+		}
+		c.lock.Unlock()
+		return int(n), nil
+	}
+}
+
+func (c *eventStream) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		c.lock.Lock()
+		if c.closed {
+			c.lock.Unlock()
+			return written, io.ErrClosedPipe
+		}
+		n := C.virStreamSend(c.stream.ptr, (*C.char)(unsafe.Pointer(&p[written])), C.size_t(len(p)-written))
+		switch {
+		case n == -2:
+			c.wait(VIR_STREAM_EVENT_WRITABLE, c.writable)
+			continue
+		case n < 0:
+			err := GetLastError()
+			c.lock.Unlock()
+			return written, err
+		}
+		c.lock.Unlock()
+		written += int(n)
+	}
+	return written, nil
+}
+
+// Close disconnects and frees the stream. The stream is finished if the
+// other end closed it, and aborted otherwise.
+func (c *eventStream) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	close(c.done)
+
+	C.virStreamEventRemoveCallback(c.stream.ptr)
+	var err error
+	if c.eof {
+		err = c.stream.Close()
+	} else {
+		err = c.stream.Abort()
+	}
+	c.stream.Free()
+	return err
+}
@@ -0,0 +1,161 @@
+// Package vncproxy serves the VNC console of virtual machines to noVNC and
+// other browser clients over WebSocket. It has no dependencies besides the
+// standard library; connecting to the VNC server is left to a dial
+// function, typically calling DialGraphics on the domain or dialing the
+// address returned by its GraphicsAddress.
+package vncproxy
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Handler upgrades requests to WebSocket connections speaking the binary
+// subprotocol and relays them to a VNC server. The RFB protocol is passed
+// through untouched, so authentication with the VNC server, if any, is up
+// to the client.
+type Handler struct {
+	// Dial connects to the VNC server for the request, typically looking
+	// up the domain named in its path or query. Required.
+	Dial func(r *http.Request) (net.Conn, error)
+
+	// CheckOrigin, if set, tells whether to accept a request given its
+	// Origin header. By default only requests without an Origin header or
+	// from the host serving the handler are accepted, so that other sites
+	// cannot use the cookies of a browser to reach the consoles. Others
+	// are refused with 403 Forbidden.
+	CheckOrigin func(r *http.Request) bool
+
+	// Authorize, if set, is called before the upgrade, after the origin
+	// check. A request for which it returns an error is refused with 403
+	// Forbidden.
+	Authorize func(r *http.Request) error
+
+	// IdleTimeout closes connections with no traffic in either direction
+	// for that long. Zero means no timeout.
+	IdleTimeout time.Duration
+}
+
+// relayBufferSize is the largest chunk of VNC output sent in one message.
+const relayBufferSize = 32 * 1024
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	subprotocol, err := checkHandshake(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	checkOrigin := h.CheckOrigin
+	if checkOrigin == nil {
+		checkOrigin = sameOrigin
+	}
+	if !checkOrigin(r) {
+		http.Error(w, "cross-origin request refused", http.StatusForbidden)
+		return
+	}
+	if h.Authorize != nil {
+		if err := h.Authorize(r); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+	}
+	vnc, err := h.Dial(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	ws, err := upgrade(w, r, subprotocol)
+	if err != nil {
+		vnc.Close()
+		return
+	}
+	h.relay(ws, vnc)
+}
+
+// sameOrigin accepts requests without an Origin header, as sent by
+// non-browser clients, and requests whose origin is the requested host.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+// relay copies data both ways until either side goes away or the
+// connection is idle for too long, then closes both.
+func (h *Handler) relay(ws *wsConn, vnc net.Conn) {
+	var lastActivity int64
+	touch := func() {
+		atomic.StoreInt64(&lastActivity, time.Now().UnixNano())
+	}
+	touch()
+
+	done := make(chan struct{})
+	var once sync.Once
+	shutdown := func() {
+		once.Do(func() {
+			close(done)
+			vnc.Close()
+			ws.Close()
+		})
+	}
+	defer shutdown()
+
+	if h.IdleTimeout > 0 {
+		go func() {
+			for {
+				deadline := time.Unix(0, atomic.LoadInt64(&lastActivity)).Add(h.IdleTimeout)
+				wait := deadline.Sub(time.Now())
+				if wait <= 0 {
+					ws.writeClose(closeNormal)
+					shutdown()
+					return
+				}
+				select {
+				case <-time.After(wait):
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer shutdown()
+		buf := make([]byte, relayBufferSize)
+		for {
+			n, err := vnc.Read(buf)
+			if n > 0 {
+				touch()
+				if ws.WriteMessage(buf[:n]) != nil {
+					return
+				}
+			}
+			if err != nil {
+				ws.writeClose(closeNormal)
+				return
+			}
+		}
+	}()
+
+	for {
+		message, err := ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		touch()
+		if _, err := vnc.Write(message); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package vncproxy
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// fakeRFBServer runs the server side of an RFB 3.8 handshake without
+// security, then echoes whatever the client sends.
+func fakeRFBServer(conn net.Conn) {
+	defer conn.Close()
+	io.WriteString(conn, "RFB 003.008\n")
+	version := make([]byte, 12)
+	if _, err := io.ReadFull(conn, version); err != nil || string(version) != "RFB 003.008\n" {
+		return
+	}
+	conn.Write([]byte{1, 1}) // One security type: None
+	choice := make([]byte, 1)
+	if _, err := io.ReadFull(conn, choice); err != nil || choice[0] != 1 {
+		return
+	}
+	conn.Write([]byte{0, 0, 0, 0}) // SecurityResult OK
+	io.Copy(conn, conn)
+}
+
+type testClient struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func dialTestProxy(t *testing.T, url, path string, header http.Header) (*testClient, *http.Response) {
+	conn, err := net.Dial("tcp", url[len("http://"):])
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest("GET", url+path, nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	req.Header.Set("Sec-WebSocket-Protocol", "binary")
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if err := req.Write(conn); err != nil {
+		t.Fatal(err)
+	}
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testClient{conn, r}, resp
+}
+
+func (c *testClient) writeFrame(fin bool, opcode byte, payload []byte) {
+	first := opcode
+	if fin {
+		first |= 0x80
+	}
+	frame := []byte{first, 0x80 | byte(len(payload))}
+	mask := []byte{0x12, 0x34, 0x56, 0x78}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	c.conn.Write(frame)
+}
+
+func (c *testClient) readFrame(t *testing.T) (byte, []byte) {
+	c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var header [2]byte
+	if _, err := io.ReadFull(c.r, header[:]); err != nil {
+		t.Fatal(err)
+	}
+	if header[1]&0x80 != 0 {
+		t.Fatal("server sent a masked frame")
+	}
+	length := int(header[1] & 0x7f)
+	if length == 126 {
+		var ext [2]byte
+		io.ReadFull(c.r, ext[:])
+		length = int(binary.BigEndian.Uint16(ext[:]))
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		t.Fatal(err)
+	}
+	return header[0] & 0x0f, payload
+}
+
+// readData reads binary messages until n bytes of data came.
+func (c *testClient) readData(t *testing.T, n int) []byte {
+	var data []byte
+	for len(data) < n {
+		opcode, payload := c.readFrame(t)
+		if opcode != opBinary {
+			t.Fatalf("got opcode %d, expected binary", opcode)
+		}
+		data = append(data, payload...)
+	}
+	return data
+}
+
+// buildTestHandler makes h dial a fake RFB server for domain "test".
+func buildTestHandler(h *Handler) *Handler {
+	h.Dial = func(r *http.Request) (net.Conn, error) {
+		if r.URL.Query().Get("domain") != "test" {
+			return nil, errors.New("no such domain")
+		}
+		proxy, server := net.Pipe()
+		go fakeRFBServer(server)
+		return proxy, nil
+	}
+	return h
+}
+
+func buildTestProxy(h *Handler) *httptest.Server {
+	return httptest.NewServer(buildTestHandler(h))
+}
+
+func TestAcceptKey(t *testing.T) {
+	// Example from RFC 6455
+	if key := acceptKey("dGhlIHNhbXBsZSBub25jZQ=="); key != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("acceptKey() == %q", key)
+	}
+}
+
+func TestHandlerRelay(t *testing.T) {
+	server := buildTestProxy(&Handler{})
+	defer server.Close()
+
+	client, resp := dialTestProxy(t, server.URL, "/vnc?domain=test", nil)
+	defer client.conn.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("got status %d, expected %d", resp.StatusCode, http.StatusSwitchingProtocols)
+	}
+	if accept := resp.Header.Get("Sec-WebSocket-Accept"); accept != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("Sec-WebSocket-Accept == %q", accept)
+	}
+	if protocol := resp.Header.Get("Sec-WebSocket-Protocol"); protocol != "binary" {
+		t.Errorf("Sec-WebSocket-Protocol == %q, expected binary", protocol)
+	}
+
+	if banner := client.readData(t, 12); string(banner) != "RFB 003.008\n" {
+		t.Fatalf("got banner %q", banner)
+	}
+	// The version is split over a fragmented message, with a ping in
+	// between.
+	client.writeFrame(false, opBinary, []byte("RFB 003"))
+	client.writeFrame(true, opPing, []byte("hi"))
+	client.writeFrame(true, opContinuation, []byte(".008\n"))
+	if opcode, payload := client.readFrame(t); opcode != opPong || string(payload) != "hi" {
+		t.Errorf("got opcode %d payload %q, expected pong", opcode, payload)
+	}
+	if types := client.readData(t, 2); !bytes.Equal(types, []byte{1, 1}) {
+		t.Fatalf("got security types %v", types)
+	}
+	client.writeFrame(true, opBinary, []byte{1})
+	if result := client.readData(t, 4); !bytes.Equal(result, []byte{0, 0, 0, 0}) {
+		t.Fatalf("got security result %v", result)
+	}
+	client.writeFrame(true, opBinary, []byte("key event"))
+	if echo := client.readData(t, 9); string(echo) != "key event" {
+		t.Errorf("got echo %q", echo)
+	}
+
+	client.writeFrame(true, opClose, []byte{0x03, 0xe8})
+	if opcode, payload := client.readFrame(t); opcode != opClose || !bytes.Equal(payload, []byte{0x03, 0xe8}) {
+		t.Errorf("got opcode %d payload %v, expected close", opcode, payload)
+	}
+}
+
+func TestHandlerRefusals(t *testing.T) {
+	server := buildTestProxy(&Handler{
+		Authorize: func(r *http.Request) error {
+			if r.Header.Get("Authorization") != "Bearer secret" {
+				return errors.New("bad token")
+			}
+			return nil
+		},
+	})
+	defer server.Close()
+
+	for _, test := range []struct {
+		header http.Header
+		status int
+	}{
+		{http.Header{"Authorization": {"Bearer wrong"}}, http.StatusForbidden},
+		{http.Header{"Authorization": {"Bearer secret"}, "Sec-Websocket-Protocol": {"base64"}}, http.StatusBadRequest},
+		{http.Header{"Authorization": {"Bearer secret"}, "Sec-Websocket-Version": {"8"}}, http.StatusBadRequest},
+		{http.Header{"Authorization": {"Bearer secret"}}, http.StatusSwitchingProtocols},
+	} {
+		client, resp := dialTestProxy(t, server.URL, "/vnc?domain=test", test.header)
+		client.conn.Close()
+		if resp.StatusCode != test.status {
+			t.Errorf("got status %d with %v, expected %d", resp.StatusCode, test.header, test.status)
+		}
+	}
+}
+
+func TestHandlerOrigin(t *testing.T) {
+	server := buildTestProxy(&Handler{})
+	defer server.Close()
+	host := server.URL[len("http://"):]
+
+	for _, test := range []struct {
+		origin string
+		status int
+	}{
+		{"http://evil.example.com", http.StatusForbidden},
+		{"http://" + host + ".example.com", http.StatusForbidden},
+		{"http://" + host, http.StatusSwitchingProtocols},
+		{"", http.StatusSwitchingProtocols},
+	} {
+		var header http.Header
+		if test.origin != "" {
+			header = http.Header{"Origin": {test.origin}}
+		}
+		client, resp := dialTestProxy(t, server.URL, "/vnc?domain=test", header)
+		client.conn.Close()
+		if resp.StatusCode != test.status {
+			t.Errorf("got status %d with origin %q, expected %d", resp.StatusCode, test.origin, test.status)
+		}
+	}
+
+	allowed := buildTestProxy(&Handler{
+		CheckOrigin: func(r *http.Request) bool {
+			return r.Header.Get("Origin") == "https://console.example.com"
+		},
+	})
+	defer allowed.Close()
+	client, resp := dialTestProxy(t, allowed.URL, "/vnc?domain=test", http.Header{"Origin": {"https://console.example.com"}})
+	client.conn.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Errorf("got status %d with an allowed origin, expected %d", resp.StatusCode, http.StatusSwitchingProtocols)
+	}
+}
+
+func TestHandlerBadDial(t *testing.T) {
+	server := buildTestProxy(&Handler{})
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/vnc?domain=test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("got status %d for a plain request, expected %d", resp.StatusCode, http.StatusBadRequest)
+	}
+
+	client, resp := dialTestProxy(t, server.URL, "/vnc?domain=other", nil)
+	client.conn.Close()
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("got status %d for an unknown domain, expected %d", resp.StatusCode, http.StatusBadGateway)
+	}
+}
+
+func TestHandlerIdleTimeout(t *testing.T) {
+	server := buildTestProxy(&Handler{IdleTimeout: 100 * time.Millisecond})
+	defer server.Close()
+
+	client, _ := dialTestProxy(t, server.URL, "/vnc?domain=test", nil)
+	defer client.conn.Close()
+	client.readData(t, 12)
+	start := time.Now()
+	if opcode, _ := client.readFrame(t); opcode != opClose {
+		t.Errorf("got opcode %d, expected close", opcode)
+	}
+	if elapsed := time.Now().Sub(start); elapsed < 50*time.Millisecond {
+		t.Errorf("closed after %s, before the idle timeout", elapsed)
+	}
+}
+
+func TestHandlerServerTimeouts(t *testing.T) {
+	server := httptest.NewUnstartedServer(buildTestHandler(&Handler{}))
+	server.Config.ReadTimeout = 100 * time.Millisecond
+	server.Config.WriteTimeout = 100 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	client, resp := dialTestProxy(t, server.URL, "/vnc?domain=test", nil)
+	defer client.conn.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("got status %d, expected %d", resp.StatusCode, http.StatusSwitchingProtocols)
+	}
+	client.readData(t, 12)
+	// The session must outlive the timeouts of the HTTP server
+	time.Sleep(300 * time.Millisecond)
+	client.writeFrame(true, opBinary, []byte("RFB 003.008\n"))
+	if types := client.readData(t, 2); !bytes.Equal(types, []byte{1, 1}) {
+		t.Errorf("got security types %v", types)
+	}
+}
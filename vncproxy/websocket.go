@@ -0,0 +1,287 @@
+package vncproxy
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Just enough of RFC 6455 to carry a binary stream: the server side of
+// the handshake, masked client frames, fragmentation and control frames.
+
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xa
+)
+
+// Close status codes.
+const (
+	closeNormal           = 1000
+	closeProtocolError    = 1002
+	closeUnsupportedData  = 1003
+	closeMessageTooBig    = 1009
+	closeAbnormalOrNoCode = 1005
+)
+
+// maxFramePayload bounds the frames accepted from clients. noVNC sends
+// small input events, so anything larger is abuse.
+const maxFramePayload = 1 << 20
+
+var (
+	errNotWebSocket    = errors.New("not a websocket handshake")
+	errBadVersion      = errors.New("unsupported websocket version")
+	errNoSubprotocol   = errors.New("client does not speak the binary subprotocol")
+	errProtocol        = errors.New("websocket protocol error")
+	errUnsupportedData = errors.New("websocket text frames are not supported")
+	errFrameTooBig     = errors.New("websocket frame too big")
+)
+
+func headerContains(h http.Header, name, token string) bool {
+	for _, value := range h[http.CanonicalHeaderKey(name)] {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func acceptKey(key string) string {
+	h := sha1.New()
+	io.WriteString(h, key+websocketGUID)
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// checkHandshake validates the upgrade request and returns the
+// subprotocol to confirm, empty if the client did not ask for one.
+func checkHandshake(r *http.Request) (string, error) {
+	if r.Method != "GET" || !headerContains(r.Header, "Connection", "upgrade") ||
+		!headerContains(r.Header, "Upgrade", "websocket") || r.Header.Get("Sec-WebSocket-Key") == "" {
+		return "", errNotWebSocket
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		return "", errBadVersion
+	}
+	if r.Header.Get("Sec-WebSocket-Protocol") == "" {
+		return "", nil
+	}
+	if !headerContains(r.Header, "Sec-WebSocket-Protocol", "binary") {
+		return "", errNoSubprotocol
+	}
+	return "binary", nil
+}
+
+// wsConn is a server side websocket connection.
+type wsConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+
+	writeLock sync.Mutex
+	closeSent bool
+}
+
+// upgrade completes the handshake checked by checkHandshake and takes over
+// the connection.
+func upgrade(w http.ResponseWriter, r *http.Request, subprotocol string) (*wsConn, error) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, errors.New("response does not support hijacking")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	// The connection may still carry the ReadTimeout and WriteTimeout of the
+	// server, idle connections are handled by the relay instead.
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(r.Header.Get("Sec-WebSocket-Key")) + "\r\n"
+	if subprotocol != "" {
+		response += "Sec-WebSocket-Protocol: " + subprotocol + "\r\n"
+	}
+	if _, err := io.WriteString(conn, response+"\r\n"); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &wsConn{conn: conn, r: rw.Reader}, nil
+}
+
+// readFrame reads one frame and unmasks its payload.
+func (c *wsConn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err = io.ReadFull(c.r, header[:]); err != nil {
+		return
+	}
+	fin = header[0]&0x80 != 0
+	opcode = header[0] & 0x0f
+	if header[0]&0x70 != 0 || header[1]&0x80 == 0 {
+		// Reserved bits without extension, or unmasked client frame
+		err = errProtocol
+		return
+	}
+
+	length := uint64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(c.r, ext[:]); err != nil {
+			return
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(c.r, ext[:]); err != nil {
+			return
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if opcode >= opClose && (length > 125 || !fin) {
+		err = errProtocol
+		return
+	}
+	if length > maxFramePayload {
+		err = errFrameTooBig
+		return
+	}
+
+	var mask [4]byte
+	if _, err = io.ReadFull(c.r, mask[:]); err != nil {
+		return
+	}
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(c.r, payload); err != nil {
+		return
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return
+}
+
+// ReadMessage returns the payload of the next binary message, answering
+// pings and close frames on the way. io.EOF is returned once the client
+// closed the connection.
+func (c *wsConn) ReadMessage() ([]byte, error) {
+	var message []byte
+	started := false
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			switch err {
+			case errProtocol:
+				c.writeClose(closeProtocolError)
+			case errFrameTooBig:
+				c.writeClose(closeMessageTooBig)
+			}
+			return nil, err
+		}
+		switch opcode {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			code := closeAbnormalOrNoCode
+			if len(payload) >= 2 {
+				code = int(binary.BigEndian.Uint16(payload))
+			}
+			c.writeClose(code)
+			return nil, io.EOF
+		case opText:
+			c.writeClose(closeUnsupportedData)
+			return nil, errUnsupportedData
+		case opBinary:
+			if started {
+				c.writeClose(closeProtocolError)
+				return nil, errProtocol
+			}
+			started = true
+			message = payload
+		case opContinuation:
+			if !started {
+				c.writeClose(closeProtocolError)
+				return nil, errProtocol
+			}
+			if len(message)+len(payload) > maxFramePayload {
+				c.writeClose(closeMessageTooBig)
+				return nil, errFrameTooBig
+			}
+			message = append(message, payload...)
+		default:
+			c.writeClose(closeProtocolError)
+			return nil, errProtocol
+		}
+		if fin {
+			return message, nil
+		}
+	}
+}
+
+func (c *wsConn) writeFrame(opcode byte, payload []byte) error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	if c.closeSent {
+		return io.ErrClosedPipe
+	}
+	if opcode == opClose {
+		c.closeSent = true
+	}
+
+	frame := make([]byte, 0, 10+len(payload))
+	frame = append(frame, 0x80|opcode)
+	switch {
+	case len(payload) < 126:
+		frame = append(frame, byte(len(payload)))
+	case len(payload) <= 0xffff:
+		frame = append(frame, 126, byte(len(payload)>>8), byte(len(payload)))
+	default:
+		var ext [8]byte
+		binary.BigEndian.PutUint64(ext[:], uint64(len(payload)))
+		frame = append(append(frame, 127), ext[:]...)
+	}
+	_, err := c.conn.Write(append(frame, payload...))
+	return err
+}
+
+// WriteMessage sends p as a binary message.
+func (c *wsConn) WriteMessage(p []byte) error {
+	return c.writeFrame(opBinary, p)
+}
+
+// writeClose starts the closing handshake. Codes that may not be sent on
+// the wire are replaced with a normal closure.
+func (c *wsConn) writeClose(code int) error {
+	if code == closeAbnormalOrNoCode {
+		code = closeNormal
+	}
+	var payload [2]byte
+	binary.BigEndian.PutUint16(payload[:], uint16(code))
+	return c.writeFrame(opClose, payload[:])
+}
+
+func (c *wsConn) Close() error {
+	return c.conn.Close()
+}